@@ -0,0 +1,52 @@
+package chrometrace
+
+import "os"
+
+// config update func
+type ConfigUpdateFunc func(config *Config)
+
+// configs that are passed to the trace writer
+type Config struct {
+	ProcessName string   // the name shown for the process lane
+	Spans       []string // base events of the spans written
+}
+
+/*
+Registers a new trace config.
+if no configs are provided, the default sets
+processName to the name of the executable,
+spans to empty
+*/
+func NewConfig(configs ...ConfigUpdateFunc) *Config {
+	processName := "gopulse"
+	if executable, err := os.Executable(); err == nil {
+		processName = executable
+	}
+
+	traceConfig := &Config{
+		ProcessName: processName,
+		Spans:       []string{},
+	}
+
+	for _, config := range configs {
+		config(traceConfig)
+	}
+
+	return traceConfig
+}
+
+// helper functions for setting trace config
+
+// sets the name shown for the process lane
+func WithProcessName(processName string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.ProcessName = processName
+	}
+}
+
+// adds the base events of spans written
+func WithSpans(events ...string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Spans = append(config.Spans, events...)
+	}
+}
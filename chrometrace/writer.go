@@ -0,0 +1,209 @@
+package chrometrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/spans"
+)
+
+// an event in the chrome trace event format
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type traceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp int64                  `json:"ts"`            // microseconds
+	Duration  int64                  `json:"dur,omitempty"` // microseconds
+	PID       int                    `json:"pid"`
+	TID       int                    `json:"tid"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+/*
+A writer that writes spans as chrome trace events, the output can be
+opened in perfetto or chrome://tracing.
+every span is written as a complete event once it ends or panics. spans
+that overlap are placed on separate thread lanes so they render side by side.
+*/
+type Writer struct {
+	id     string
+	config *Config
+	output io.Writer
+	closer io.Closer
+
+	mu      sync.Mutex
+	buffer  *bufio.Writer
+	written int
+	lanes   []int64 // the time each lane is busy until, in microseconds
+	closed  bool
+	tracker *spans.Tracker
+}
+
+// writer will implement the telemetry handler interface
+
+func NewWriter(id string, output io.Writer, config *Config) *Writer {
+	writer := &Writer{
+		id:     id,
+		config: config,
+		output: output,
+		buffer: bufio.NewWriter(output),
+		lanes:  make([]int64, 0),
+	}
+	writer.tracker = spans.NewTracker(writer.writeSpan, config.Spans...)
+
+	// the json array format does not require the closing bracket,
+	// so the trace can be opened even if the process exits early
+	writer.buffer.WriteString("[\n")
+	writer.writeEvent(traceEvent{
+		Name:  "process_name",
+		Phase: "M",
+		PID:   os.Getpid(),
+		Args:  map[string]interface{}{"name": config.ProcessName},
+	})
+
+	return writer
+}
+
+// creates the file and writes the trace to it
+func Create(id string, path string, config *Config) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := NewWriter(id, file, config)
+	writer.closer = file
+
+	return writer, nil
+}
+
+func (w *Writer) ID() string {
+	return w.id
+}
+
+func (w *Writer) Config() interface{} {
+	return w.config
+}
+
+func (w *Writer) AttachedHandlers() []telemetry.EventRegistrar {
+	return w.tracker.Handlers()
+}
+
+// flushes the buffered events to the output
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buffer.Flush()
+}
+
+// closes the trace and the file it was created with
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	w.buffer.WriteString("\n]\n")
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+
+	return nil
+}
+
+// private methods
+
+func (w *Writer) writeSpan(span spans.Span) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	start := span.Start.UnixMicro()
+	end := span.End.UnixMicro()
+
+	args := make(map[string]interface{})
+	for key, value := range span.Metadata() {
+		args[key] = argValue(value)
+	}
+	args[telemetry.TraceIDKey] = span.TraceID
+	args[telemetry.SpanIDKey] = span.SpanID
+	if span.ParentSpanID != "" {
+		args[telemetry.ParentSpanIDKey] = span.ParentSpanID
+	}
+
+	if span.Panicked {
+		args["error"] = fmt.Sprint(span.Error)
+		args["stackTrace"] = span.StackTrace
+	}
+
+	w.writeEvent(traceEvent{
+		Name:      span.Name,
+		Category:  "span",
+		Phase:     "X",
+		Timestamp: start,
+		Duration:  end - start,
+		PID:       os.Getpid(),
+		TID:       w.lane(start, end),
+		Args:      args,
+	})
+}
+
+// returns the first lane free at the start of the span and marks it busy until its end
+func (w *Writer) lane(start int64, end int64) int {
+	for i, busyUntil := range w.lanes {
+		if busyUntil <= start {
+			w.lanes[i] = end
+			return i + 1
+		}
+	}
+
+	w.lanes = append(w.lanes, end)
+	return len(w.lanes)
+}
+
+// write the event, the caller must hold the lock
+func (w *Writer) writeEvent(event traceEvent) {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	if w.written > 0 {
+		w.buffer.WriteString(",\n")
+	}
+	w.buffer.Write(encoded)
+	w.written++
+}
+
+// keep the values json can encode, anything else is formatted
+func argValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return value
+}
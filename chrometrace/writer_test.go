@@ -0,0 +1,165 @@
+package chrometrace_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/chrometrace"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+type traceEvent struct {
+	Name  string                 `json:"name"`
+	Phase string                 `json:"ph"`
+	TS    int64                  `json:"ts"`
+	Dur   int64                  `json:"dur"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args"`
+}
+
+func sleepingSpan(delay time.Duration) telemetry.SpanFunc[any] {
+	return func() (any, error, map[string]interface{}, map[string]interface{}) {
+		time.Sleep(delay)
+		return nil, nil, map[string]interface{}{}, map[string]interface{}{"result": "ok"}
+	}
+}
+
+// returns the complete events of the trace
+func completeEvents(t *testing.T, trace []byte) []traceEvent {
+	var events []traceEvent
+	if err := json.Unmarshal(trace, &events); err != nil {
+		t.Fatalf("trace is not valid json: %v\n%s", err, trace)
+	}
+
+	complete := make([]traceEvent, 0)
+	for _, event := range events {
+		if event.Phase == "X" {
+			complete = append(complete, event)
+		}
+	}
+
+	return complete
+}
+
+func TestWriter(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	writer := chrometrace.NewWriter("trace", &output, chrometrace.NewConfig(
+		chrometrace.WithProcessName("checkout"),
+		chrometrace.WithSpans("gopulse.request"),
+	))
+	telemetry.AddHandlers(writer)
+
+	telemetry.TriggerSpan("gopulse.request", map[string]interface{}{"route": "/cart"}, sleepingSpan(5*time.Millisecond))
+	func() {
+		defer func() {
+			recover()
+		}()
+
+		telemetry.TriggerSpan("gopulse.request", map[string]interface{}{}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			panic(errors.New("boom"))
+		})
+	}()
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close the writer: %v", err)
+	}
+
+	var events []traceEvent
+	if err := json.Unmarshal(output.Bytes(), &events); err != nil {
+		t.Fatalf("trace is not valid json: %v", err)
+	}
+
+	if events[0].Phase != "M" || events[0].Args["name"] != "checkout" {
+		t.Errorf("expected the process name metadata event, got %+v", events[0])
+	}
+
+	complete := completeEvents(t, output.Bytes())
+	if len(complete) != 2 {
+		t.Fatalf("expected 2 complete events, got %d", len(complete))
+	}
+
+	request := complete[0]
+	if request.Name != "gopulse.request" || request.Dur < 5000 || request.PID != os.Getpid() || request.Args["route"] != "/cart" {
+		t.Errorf("unexpected event %+v", request)
+	}
+
+	if complete[1].Args["error"] != "boom" {
+		t.Errorf("expected the panic in the args, got %+v", complete[1].Args)
+	}
+}
+
+func TestWriterLanes(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	writer := chrometrace.NewWriter("trace", &output, chrometrace.NewConfig(chrometrace.WithSpans("gopulse.job")))
+	telemetry.AddHandlers(writer)
+
+	// overlapping spans
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, sleepingSpan(30*time.Millisecond))
+		}()
+	}
+	wg.Wait()
+
+	// a span after the others have finished reuses a lane
+	telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, sleepingSpan(0))
+	writer.Close()
+
+	complete := completeEvents(t, output.Bytes())
+	if len(complete) != 4 {
+		t.Fatalf("expected 4 complete events, got %d", len(complete))
+	}
+
+	lanes := map[int]bool{}
+	for _, event := range complete[:3] {
+		lanes[event.TID] = true
+	}
+	if len(lanes) != 3 {
+		t.Errorf("expected overlapping spans on 3 lanes, got %v", lanes)
+	}
+
+	if !lanes[complete[3].TID] {
+		t.Errorf("expected the last span to reuse a lane, got %d", complete[3].TID)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	writer, err := chrometrace.Create("trace", path, chrometrace.NewConfig(chrometrace.WithSpans("gopulse.job")))
+	if err != nil {
+		t.Fatalf("failed to create the trace: %v", err)
+	}
+	telemetry.AddHandlers(writer)
+
+	telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, sleepingSpan(0))
+	writer.Close()
+
+	trace, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the trace: %v", err)
+	}
+
+	if complete := completeEvents(t, trace); len(complete) != 1 {
+		t.Errorf("expected 1 complete event, got %d", len(complete))
+	}
+}
@@ -0,0 +1,93 @@
+package enrich
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// keys the processors add to the metadata and measurements
+const (
+	HostnameKey  = "hostname"
+	PIDKey       = "pid"
+	ServiceKey   = "service"
+	VersionKey   = "version"
+	GoVersionKey = "go_version"
+	RevisionKey  = "revision"
+	SequenceKey  = "sequence"
+)
+
+/*
+Returns a processor adding the hostname to the metadata.
+the hostname is read once, when the processor is created.
+*/
+func Hostname() telemetry.ProcessorFunc {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return Static(map[string]interface{}{HostnameKey: hostname})
+}
+
+// returns a processor adding the process id to the metadata
+func PID() telemetry.ProcessorFunc {
+	return Static(map[string]interface{}{PIDKey: os.Getpid()})
+}
+
+// returns a processor adding the service name to the metadata
+func Service(name string) telemetry.ProcessorFunc {
+	return Static(map[string]interface{}{ServiceKey: name})
+}
+
+/*
+Returns a processor adding the build info to the metadata.
+version is the version of the main module, go_version is the go version the
+binary was built with and revision is the vcs revision, if it was stamped.
+*/
+func BuildInfo() telemetry.ProcessorFunc {
+	metadata := map[string]interface{}{
+		GoVersionKey: runtime.Version(),
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		metadata[VersionKey] = info.Main.Version
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				metadata[RevisionKey] = setting.Value
+			}
+		}
+	}
+
+	return Static(metadata)
+}
+
+/*
+Returns a processor adding a sequence number to the measurements.
+the sequence is incremented for every event, so events of a provider can be
+ordered even when their handlers run concurrently.
+*/
+func Sequence() telemetry.ProcessorFunc {
+	var sequence atomic.Uint64
+
+	return func(event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+		measurement[SequenceKey] = sequence.Add(1)
+	}
+}
+
+/*
+Returns a processor adding the values to the metadata.
+values already in the metadata of an event are not overridden.
+*/
+func Static(values map[string]interface{}) telemetry.ProcessorFunc {
+	return func(event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+		for key, value := range values {
+			if _, ok := metadata[key]; !ok {
+				metadata[key] = value
+			}
+		}
+	}
+}
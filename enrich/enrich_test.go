@@ -0,0 +1,69 @@
+package enrich_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/enrich"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestProcessors(t *testing.T) {
+	t.Run("should enrich the metadata", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig(
+			telemetry.WithProcessors(enrich.Hostname(), enrich.PID(), enrich.Service("billing"), enrich.BuildInfo()),
+		))
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.event.test")
+
+		telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+
+		hostname, _ := os.Hostname()
+		if !mailer.AssertReceived("gopulse.event.test", func(event string, box ...mailbox.MailData) bool {
+			metadata := box[0].Metadata
+			return metadata[enrich.HostnameKey] == hostname &&
+				metadata[enrich.PIDKey] == os.Getpid() &&
+				metadata[enrich.ServiceKey] == "billing" &&
+				metadata[enrich.GoVersionKey] == runtime.Version()
+		}) {
+			t.Errorf("should assert received the enriched metadata")
+		}
+	})
+
+	t.Run("should not override the metadata of the event", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig(
+			telemetry.WithProcessors(enrich.Service("billing")),
+		))
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.event.test")
+
+		telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{enrich.ServiceKey: "invoices"})
+
+		if !mailer.AssertReceived("gopulse.event.test", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata[enrich.ServiceKey] == "invoices"
+		}) {
+			t.Errorf("should keep the service of the event")
+		}
+	})
+
+	t.Run("should number the events", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig(
+			telemetry.WithProcessors(enrich.Sequence()),
+		))
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.event.test", "gopulse.event.other")
+
+		telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+		telemetry.TriggerEvent("gopulse.event.other", map[string]interface{}{}, map[string]interface{}{})
+		telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+
+		if !mailer.AssertReceived("gopulse.event.test", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Measurement[enrich.SequenceKey] == uint64(1) && box[1].Measurement[enrich.SequenceKey] == uint64(3)
+		}) {
+			t.Errorf("should number the events in order")
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package example
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// the output format of the console handler
+type Format int
+
+const (
+	FormatLogfmt Format = iota // key=value pairs, easy to grep and parse
+	FormatText                 // compact human readable lines
+	FormatTree                 // human readable lines with spans nested by their start and end
+)
+
+// ansi color codes of the levels
+var levelColors = map[string]string{
+	"debug": "\033[90m",
+	"info":  "\033[36m",
+	"warn":  "\033[33m",
+	"error": "\033[31m",
+	"panic": "\033[1;35m",
+}
+
+const colorReset = "\033[0m"
+
+// the most spans nested in the tree, the outermost is dropped when a span never ends
+const maxOpenSpans = 32
+
+// create a console handler
+
+type ConsoleHandler struct {
+	id       string
+	output   io.Writer
+	format   Format
+	color    bool
+	handlers []telemetry.EventRegistrar
+
+	mu   sync.Mutex
+	open []string // span ids of the spans started and not yet ended, outermost first
+}
+
+func NewConsoleHandler(id string, output io.Writer, format Format) *ConsoleHandler {
+	return &ConsoleHandler{
+		id:       id,
+		output:   output,
+		format:   format,
+		handlers: make([]telemetry.EventRegistrar, 0),
+		open:     make([]string, 0),
+	}
+}
+
+// colors the levels of the text and tree formats
+func (c *ConsoleHandler) WithColor(color bool) *ConsoleHandler {
+	c.color = color
+	return c
+}
+
+// prints the events at the level
+func (c *ConsoleHandler) Log(level string, events ...string) *ConsoleHandler {
+	for _, event := range events {
+		c.handlers = append(c.handlers, telemetry.EventRegistrar{
+			Event:   event,
+			Handler: c.HandleEvent(level),
+		})
+	}
+
+	return c
+}
+
+// prints the start and end of the spans at debug and info and their panics at panic
+func (c *ConsoleHandler) Spans(events ...string) *ConsoleHandler {
+	for _, event := range events {
+		c.Log("debug", event+telemetry.SpanStartSuffix)
+		c.Log("info", event+telemetry.SpanEndSuffix)
+		c.Log("panic", event+telemetry.SpanPanicSuffix)
+	}
+
+	return c
+}
+
+func (c *ConsoleHandler) ID() string {
+	return c.id
+}
+
+func (c *ConsoleHandler) Config() interface{} {
+	return nil
+}
+
+func (c *ConsoleHandler) AttachedHandlers() []telemetry.EventRegistrar {
+	return c.handlers
+}
+
+func (c *ConsoleHandler) HandleEvent(level string) telemetry.HandleEventFunc {
+	return func(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		var line string
+		switch c.format {
+		case FormatLogfmt:
+			line = c.logfmt(time.Now(), level, event, measurement, metadata)
+		case FormatTree:
+			line = c.tree(time.Now(), level, event, measurement, metadata)
+		default:
+			line = c.text(time.Now(), level, event, measurement, metadata, "")
+		}
+
+		io.WriteString(c.output, line+"\n")
+	}
+}
+
+// private methods
+
+func (c *ConsoleHandler) logfmt(now time.Time, level string, event string, measurement map[string]interface{}, metadata map[string]interface{}) string {
+	pairs := []string{
+		"time=" + now.Format(time.RFC3339Nano),
+		"level=" + level,
+		"event=" + logfmtValue(event),
+	}
+	pairs = append(pairs, logfmtPairs("measurement.", measurement)...)
+	pairs = append(pairs, logfmtPairs("metadata.", metadata)...)
+
+	return strings.Join(pairs, " ")
+}
+
+func (c *ConsoleHandler) text(now time.Time, level string, event string, measurement map[string]interface{}, metadata map[string]interface{}, indent string) string {
+	var line strings.Builder
+	line.WriteString(now.Format("15:04:05.000"))
+	line.WriteByte(' ')
+	line.WriteString(c.colorize(level, fmt.Sprintf("%-5s", strings.ToUpper(level))))
+	line.WriteByte(' ')
+	line.WriteString(indent)
+	line.WriteString(event)
+
+	if pairs := textPairs(measurement); len(pairs) > 0 {
+		line.WriteByte(' ')
+		line.WriteString(strings.Join(pairs, " "))
+	}
+
+	if pairs := textPairs(metadata); len(pairs) > 0 {
+		line.WriteString(" | ")
+		line.WriteString(strings.Join(pairs, " "))
+	}
+
+	return line.String()
+}
+
+// prints spans indented by the spans still open when they started
+func (c *ConsoleHandler) tree(now time.Time, level string, event string, measurement map[string]interface{}, metadata map[string]interface{}) string {
+	spanID, ok := measurement[telemetry.SpanIDKey].(string)
+	if !ok {
+		return c.text(now, level, event, measurement, metadata, strings.Repeat("  ", len(c.open)))
+	}
+
+	// the ids only pair the events, they are noise in the tree
+	trimmed := make(map[string]interface{}, len(measurement))
+	for key, value := range measurement {
+		if key != telemetry.SpanIDKey && key != telemetry.TraceIDKey && key != "start_time" && key != "end_time" {
+			trimmed[key] = value
+		}
+	}
+
+	if strings.HasSuffix(event, telemetry.SpanStartSuffix) {
+		// a span whose end or panic never arrives would stay open forever
+		if len(c.open) >= maxOpenSpans {
+			c.open = append(c.open[:0], c.open[1:]...)
+		}
+
+		depth := len(c.open)
+		c.open = append(c.open, spanID)
+		return c.text(now, level, event, trimmed, metadata, strings.Repeat("  ", depth)+"┌ ")
+	}
+
+	depth := len(c.open)
+	for i, open := range c.open {
+		if open == spanID {
+			depth = i
+			c.open = append(c.open[:i], c.open[i+1:]...)
+			break
+		}
+	}
+
+	// the stack trace is too long for a tree line
+	if strings.HasSuffix(event, telemetry.SpanPanicSuffix) {
+		trimmedMetadata := make(map[string]interface{}, len(metadata))
+		for key, value := range metadata {
+			if key != "stackTrace" {
+				trimmedMetadata[key] = value
+			}
+		}
+		metadata = trimmedMetadata
+	}
+
+	return c.text(now, level, event, trimmed, metadata, strings.Repeat("  ", depth)+"└ ")
+}
+
+func (c *ConsoleHandler) colorize(level string, value string) string {
+	color, ok := levelColors[level]
+	if !c.color || !ok {
+		return value
+	}
+
+	return color + value + colorReset
+}
+
+// returns the key=value pairs ordered by key
+func textPairs(values map[string]interface{}) []string {
+	keys := sortedKeys(values)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, values[key]))
+	}
+
+	return pairs
+}
+
+// returns the logfmt pairs ordered by key
+func logfmtPairs(prefix string, values map[string]interface{}) []string {
+	keys := sortedKeys(values)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, prefix+key+"="+logfmtValue(values[key]))
+	}
+
+	return pairs
+}
+
+// returns the value, quoted if it contains spaces, quotes or equal signs
+func logfmtValue(value interface{}) string {
+	var formatted string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		formatted = v
+	case error:
+		formatted = v.Error()
+	default:
+		formatted = fmt.Sprint(v)
+	}
+
+	if formatted == "" || strings.ContainsAny(formatted, " =\"\n\t") {
+		return strconv.Quote(formatted)
+	}
+
+	return formatted
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,109 @@
+package example_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/example"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestConsoleLogfmt(t *testing.T) {
+	// create a telemetry instance
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	telemetry.AddHandlers(example.NewConsoleHandler("console", &output, example.FormatLogfmt).Log("error", "gopulse.event.test.error"))
+
+	telemetry.TriggerEvent("gopulse.event.test.error", map[string]interface{}{
+		"count": 3,
+	}, map[string]interface{}{
+		"result": "error",
+		"error":  "test error",
+	})
+
+	line := strings.TrimSpace(output.String())
+	if !strings.HasPrefix(line, "time=") {
+		t.Errorf("expected the line to start with the time, got %s", line)
+	}
+
+	expected := `level=error event=gopulse.event.test.error measurement.count=3 metadata.error="test error" metadata.result=error`
+	if !strings.HasSuffix(line, expected) {
+		t.Errorf("unexpected line\n got: %s\nwant suffix: %s", line, expected)
+	}
+}
+
+func TestConsoleText(t *testing.T) {
+	// create a telemetry instance
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	telemetry.AddHandlers(example.NewConsoleHandler("console", &output, example.FormatText).WithColor(true).Log("error", "gopulse.event.test.error"))
+
+	telemetry.TriggerEvent("gopulse.event.test.error", map[string]interface{}{"count": 3}, map[string]interface{}{"result": "error"})
+
+	line := output.String()
+	if !strings.Contains(line, "\033[31mERROR\033[0m gopulse.event.test.error count=3 | result=error") {
+		t.Errorf("expected a colored text line, got %q", line)
+	}
+}
+
+func TestConsoleTree(t *testing.T) {
+	// create a telemetry instance
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	telemetry.AddHandlers(example.NewConsoleHandler("console", &output, example.FormatTree).Spans("gopulse.request", "gopulse.query"))
+
+	span := func() (any, error, map[string]interface{}, map[string]interface{}) {
+		return nil, nil, map[string]interface{}{}, map[string]interface{}{}
+	}
+
+	telemetry.TriggerSpan("gopulse.request", map[string]interface{}{"route": "/cart"}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+		telemetry.TriggerSpan("gopulse.query", map[string]interface{}{}, span)
+		return span()
+	})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", lines)
+	}
+
+	expected := []string{
+		"┌ gopulse.request.start | route=/cart",
+		"  ┌ gopulse.query.start",
+		"  └ gopulse.query.end duration=",
+		"└ gopulse.request.end duration=",
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, expected[i]) {
+			t.Errorf("line %d\n got: %s\nwant: %s", i, line, expected[i])
+		}
+
+		if strings.Contains(line, "span_id") {
+			t.Errorf("span ids should not be printed in the tree, got %s", line)
+		}
+	}
+}
+
+func TestConsoleTreeDropsUnendedSpans(t *testing.T) {
+	// create a telemetry instance
+	provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	provider.AddHandlers(example.NewConsoleHandler("console", &output, example.FormatTree).Spans("gopulse.request"))
+
+	// starts whose end never arrives
+	for i := 0; i < 100; i++ {
+		provider.TriggerEvent("gopulse.request.start", map[string]interface{}{telemetry.SpanIDKey: fmt.Sprint(i)}, map[string]interface{}{})
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	last := lines[len(lines)-1]
+	if depth := strings.Count(last[:strings.Index(last, "┌")], "  "); depth > 32 {
+		t.Errorf("expected the spans never ended to be dropped, nested %d deep", depth)
+	}
+}
@@ -1,6 +1,7 @@
 package example_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -92,9 +93,14 @@ func TestLogTelemetryAsync(t *testing.T) {
 		"error":  nil,
 	})
 
-	// sleep for 100ms
+	// wait for the queued handlers to run
 	// this is to ensure the events are logged
-	time.Sleep(100 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := telemetry.(*providers.TelemetryProvider).WaitIdle(ctx, 0); err != nil {
+		t.Errorf("telemetry should become idle: %v", err)
+	}
 }
 
 func TestLogTelemetrySpan(t *testing.T) {
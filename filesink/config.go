@@ -0,0 +1,97 @@
+package filesink
+
+import "time"
+
+// config update func
+type ConfigUpdateFunc func(config *Config)
+
+// configs that are passed to the file sink
+type Config struct {
+	Path         string        // the file events are written to
+	Events       []string      // the events written
+	MaxSize      int64         // the size in bytes a file is rotated at, zero disables rotating by size
+	MaxAge       time.Duration // the age a file is rotated at, zero disables rotating by age
+	MaxBackups   int           // the number of rotated files kept, zero keeps every file
+	BufferSize   int           // the size of the write buffer
+	SyncInterval time.Duration // how often the buffer is flushed and the file synced to disk
+}
+
+/*
+Registers a new file sink config.
+if no configs are provided, the default sets
+path to gopulse.jsonl,
+events to empty,
+maxSize to 100MB,
+maxAge to 24h,
+maxBackups to 7,
+bufferSize to 64KB,
+syncInterval to 1s
+*/
+func NewConfig(configs ...ConfigUpdateFunc) *Config {
+	sinkConfig := &Config{
+		Path:         "gopulse.jsonl",
+		Events:       []string{},
+		MaxSize:      100 * 1024 * 1024,
+		MaxAge:       24 * time.Hour,
+		MaxBackups:   7,
+		BufferSize:   64 * 1024,
+		SyncInterval: time.Second,
+	}
+
+	for _, config := range configs {
+		config(sinkConfig)
+	}
+
+	return sinkConfig
+}
+
+// helper functions for setting file sink config
+
+// sets the file events are written to
+func WithPath(path string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Path = path
+	}
+}
+
+// adds the events written
+func WithEvents(events ...string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Events = append(config.Events, events...)
+	}
+}
+
+// sets the size in bytes a file is rotated at
+func WithMaxSize(maxSize int64) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxSize = maxSize
+	}
+}
+
+// sets the age a file is rotated at
+func WithMaxAge(maxAge time.Duration) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxAge = maxAge
+	}
+}
+
+// sets the number of rotated files kept
+func WithMaxBackups(maxBackups int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxBackups = maxBackups
+	}
+}
+
+// sets the size of the write buffer
+func WithBufferSize(bufferSize int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.BufferSize = bufferSize
+	}
+}
+
+// sets how often the buffer is flushed and the file synced to disk
+func WithSyncInterval(syncInterval time.Duration) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.SyncInterval = syncInterval
+	}
+}
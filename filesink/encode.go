@@ -0,0 +1,58 @@
+package filesink
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+/*
+Returns a value json can encode.
+errors are written as their message and maps and slices are walked so
+nested values are handled too. values json can not encode, such as
+channels and funcs, are written as their type, and anything else json
+fails on is formatted with its fields.
+*/
+func encodable(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case map[string]interface{}:
+		encoded := make(map[string]interface{}, len(v))
+		for key, nested := range v {
+			encoded[key] = encodable(nested)
+		}
+		return encoded
+	case []interface{}:
+		encoded := make([]interface{}, len(v))
+		for i, nested := range v {
+			encoded[i] = encodable(nested)
+		}
+		return encoded
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("<%s>", reflect.TypeOf(value))
+	}
+
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+
+	return value
+}
+
+// returns a copy of the values json can encode
+func encodableMap(values map[string]interface{}) map[string]interface{} {
+	encoded := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		encoded[key] = encodable(value)
+	}
+
+	return encoded
+}
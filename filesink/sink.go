@@ -0,0 +1,292 @@
+package filesink
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// format of the timestamp appended to rotated files, sorts chronologically
+const rotatedTimeFormat = "20060102T150405.000000000"
+
+// a line written for every event
+type record struct {
+	Time        string                 `json:"time"`
+	Event       string                 `json:"event"`
+	Measurement map[string]interface{} `json:"measurement"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+/*
+A sink that writes every event as a json object on its own line.
+writes are buffered, the buffer is flushed and the file synced on every
+sync interval. the file is rotated when it reaches the max size or age,
+rotated files are renamed with a timestamp suffix and the oldest are
+removed beyond the max backups.
+*/
+type Sink struct {
+	id     string
+	config *Config
+
+	mu      sync.Mutex
+	file    *os.File
+	buffer  *bufio.Writer
+	size    int64     // bytes written to the current file
+	opened  time.Time // when the current file was opened
+	closed  bool
+	dropped atomic.Uint64 // events that failed to write
+
+	done     chan struct{}
+	stopped  sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// returned by NewSink when the sync interval is not positive
+var ErrInvalidSyncInterval = errors.New("filesink: the sync interval must be positive")
+
+// sink will implement the telemetry handler interface
+
+func NewSink(id string, config *Config) (*Sink, error) {
+	if config.SyncInterval <= 0 {
+		return nil, ErrInvalidSyncInterval
+	}
+
+	sink := &Sink{
+		id:     id,
+		config: config,
+		done:   make(chan struct{}),
+	}
+
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	sink.stopped.Add(1)
+	go sink.syncLoop()
+
+	return sink, nil
+}
+
+func (s *Sink) ID() string {
+	return s.id
+}
+
+func (s *Sink) Config() interface{} {
+	return s.config
+}
+
+func (s *Sink) AttachedHandlers() []telemetry.EventRegistrar {
+	registrars := make([]telemetry.EventRegistrar, 0, len(s.config.Events))
+	for _, event := range s.config.Events {
+		registrars = append(registrars, telemetry.EventRegistrar{
+			Event:   event,
+			Handler: s.handleEvent,
+		})
+	}
+
+	return registrars
+}
+
+// returns the number of events that failed to write
+func (s *Sink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// flushes the buffer and syncs the file to disk
+func (s *Sink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	return s.sync()
+}
+
+// stops the sync loop, flushes the buffer and closes the file
+func (s *Sink) Close() error {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		s.stopped.Wait()
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if err := s.sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+
+	return s.file.Close()
+}
+
+// private methods
+
+func (s *Sink) syncLoop() {
+	defer s.stopped.Done()
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.Sync()
+		}
+	}
+}
+
+func (s *Sink) handleEvent(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+	now := time.Now()
+
+	line, err := json.Marshal(record{
+		Time:        now.UTC().Format(time.RFC3339Nano),
+		Event:       event,
+		Measurement: encodableMap(measurement),
+		Metadata:    encodableMap(metadata),
+	})
+	if err != nil {
+		s.dropped.Add(1)
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		s.dropped.Add(1)
+		return
+	}
+
+	if s.shouldRotate(int64(len(line)), now) {
+		if err := s.rotate(); err != nil {
+			s.dropped.Add(1)
+			return
+		}
+	}
+
+	written, err := s.buffer.Write(line)
+	s.size += int64(written)
+	if err != nil {
+		s.dropped.Add(1)
+	}
+}
+
+// reports whether the line does not fit in the current file, the caller must hold the lock
+func (s *Sink) shouldRotate(size int64, now time.Time) bool {
+	// a file always holds at least a line
+	if s.size == 0 {
+		return false
+	}
+
+	if s.config.MaxSize > 0 && s.size+size > s.config.MaxSize {
+		return true
+	}
+
+	return s.config.MaxAge > 0 && now.Sub(s.opened) >= s.config.MaxAge
+}
+
+// open the file, appending to it if it exists
+func (s *Sink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.config.Path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.buffer = bufio.NewWriterSize(file, s.config.BufferSize)
+	s.size = info.Size()
+	s.opened = time.Now()
+
+	return nil
+}
+
+// close the current file, rename it with a timestamp and open a new one
+func (s *Sink) rotate() error {
+	if err := s.sync(); err != nil {
+		return err
+	}
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	rotated := s.config.Path + "." + time.Now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(s.config.Path, rotated); err != nil {
+		return err
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	return s.removeBackups()
+}
+
+// remove the oldest rotated files beyond the max backups
+func (s *Sink) removeBackups() error {
+	if s.config.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(s.config.Path + ".*")
+	if err != nil {
+		return err
+	}
+
+	// only consider the files named by rotate
+	rotated := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		suffix := strings.TrimPrefix(backup, s.config.Path+".")
+		if _, err := time.Parse(rotatedTimeFormat, suffix); err == nil {
+			rotated = append(rotated, backup)
+		}
+	}
+	sort.Strings(rotated)
+
+	for len(rotated) > s.config.MaxBackups {
+		if err := os.Remove(rotated[0]); err != nil {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+
+	return nil
+}
+
+// flush the buffer and sync the file, the caller must hold the lock
+func (s *Sink) sync() error {
+	if err := s.buffer.Flush(); err != nil {
+		return err
+	}
+
+	return s.file.Sync()
+}
@@ -0,0 +1,244 @@
+package filesink_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/filesink"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+// read the json lines of the file
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	lines := make([]map[string]interface{}, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid json line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func newSink(t *testing.T, configs ...filesink.ConfigUpdateFunc) *filesink.Sink {
+	sink, err := filesink.NewSink("file", filesink.NewConfig(configs...))
+	if err != nil {
+		t.Fatalf("failed to create the sink: %v", err)
+	}
+	t.Cleanup(func() {
+		sink.Close()
+	})
+
+	return sink
+}
+
+type user struct {
+	Name  string
+	Email string
+}
+
+func TestSinkWritesJSONLines(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := newSink(t, filesink.WithPath(path), filesink.WithEvents("gopulse.event.test"), filesink.WithSyncInterval(time.Hour))
+	telemetry.AddHandlers(sink)
+
+	telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{
+		"count": 3,
+	}, map[string]interface{}{
+		"error":   errors.New("boom"),
+		"user":    user{Name: "ada", Email: "ada@example.com"},
+		"done":    make(chan struct{}),
+		"nested":  map[string]interface{}{"cause": errors.New("nested boom")},
+		"handler": func() {},
+	})
+
+	if err := sink.Sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+
+	line := lines[0]
+	if line["event"] != "gopulse.event.test" || line["measurement"].(map[string]interface{})["count"] != float64(3) {
+		t.Errorf("unexpected line %v", line)
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, line["time"].(string)); err != nil {
+		t.Errorf("expected an rfc3339 timestamp, got %v", line["time"])
+	}
+
+	metadata := line["metadata"].(map[string]interface{})
+	if metadata["error"] != "boom" || metadata["done"] != "<chan struct {}>" || metadata["handler"] != "<func()>" {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+
+	if metadata["user"].(map[string]interface{})["Email"] != "ada@example.com" {
+		t.Errorf("expected the struct to be encoded, got %v", metadata["user"])
+	}
+
+	if metadata["nested"].(map[string]interface{})["cause"] != "nested boom" {
+		t.Errorf("expected nested errors to be encoded, got %v", metadata["nested"])
+	}
+}
+
+func TestSinkFlushesOnClose(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := newSink(t, filesink.WithPath(path), filesink.WithEvents("gopulse.event.test"), filesink.WithSyncInterval(time.Hour))
+	telemetry.AddHandlers(sink)
+
+	telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+
+	// nothing is written until the buffer is flushed
+	if lines := readLines(t, path); len(lines) != 0 {
+		t.Errorf("expected the line to be buffered, got %d lines", len(lines))
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	if lines := readLines(t, path); len(lines) != 1 {
+		t.Errorf("expected the line to be flushed on close, got %d lines", len(lines))
+	}
+
+	// events after closing are dropped
+	telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+	if sink.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", sink.Dropped())
+	}
+}
+
+func TestSinkSyncsPeriodically(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := newSink(t, filesink.WithPath(path), filesink.WithEvents("gopulse.event.test"), filesink.WithSyncInterval(10*time.Millisecond))
+	telemetry.AddHandlers(sink)
+
+	telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+
+	deadline := time.Now().Add(time.Second)
+	for len(readLines(t, path)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if lines := readLines(t, path); len(lines) != 1 {
+		t.Errorf("expected the line to be synced on the interval, got %d lines", len(lines))
+	}
+}
+
+func TestSinkRejectsInvalidSyncInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := filesink.NewSink("file", filesink.NewConfig(filesink.WithPath(path), filesink.WithSyncInterval(interval)))
+		if !errors.Is(err, filesink.ErrInvalidSyncInterval) {
+			t.Errorf("expected an invalid sync interval error for %v, got %v", interval, err)
+		}
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the file not to be created, got %v", err)
+	}
+}
+
+func TestSinkRotates(t *testing.T) {
+	t.Run("should rotate by size and keep the max backups", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "events.jsonl")
+		sink := newSink(t,
+			filesink.WithPath(path),
+			filesink.WithEvents("gopulse.event.test"),
+			filesink.WithMaxSize(200),
+			filesink.WithMaxBackups(2),
+			filesink.WithSyncInterval(time.Hour),
+		)
+		telemetry.AddHandlers(sink)
+
+		for i := 0; i < 20; i++ {
+			telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{"count": i}, map[string]interface{}{})
+		}
+		sink.Close()
+
+		entries, _ := os.ReadDir(dir)
+		backups := 0
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), "events.jsonl.") {
+				backups++
+			}
+
+			info, _ := entry.Info()
+			if info.Size() > 200 {
+				t.Errorf("%s is %d bytes, larger than the max size", entry.Name(), info.Size())
+			}
+		}
+
+		if backups != 2 {
+			t.Errorf("expected 2 backups, got %d", backups)
+		}
+
+		// the newest events are in the current file
+		lines := readLines(t, path)
+		if last := lines[len(lines)-1]; last["measurement"].(map[string]interface{})["count"] != float64(19) {
+			t.Errorf("expected the last event in the current file, got %v", last)
+		}
+	})
+
+	t.Run("should rotate by age", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "events.jsonl")
+		sink := newSink(t,
+			filesink.WithPath(path),
+			filesink.WithEvents("gopulse.event.test"),
+			filesink.WithMaxAge(20*time.Millisecond),
+			filesink.WithSyncInterval(time.Hour),
+		)
+		telemetry.AddHandlers(sink)
+
+		telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+		time.Sleep(30 * time.Millisecond)
+		telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{}, map[string]interface{}{})
+		sink.Close()
+
+		backups, _ := filepath.Glob(path + ".*")
+		if len(backups) != 1 {
+			t.Errorf("expected 1 backup, got %v", backups)
+		}
+
+		if lines := readLines(t, path); len(lines) != 1 {
+			t.Errorf("expected 1 line in the current file, got %d", len(lines))
+		}
+	})
+}
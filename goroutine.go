@@ -0,0 +1,162 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// goroutine option func
+type GoOptionFunc func(config *goConfig)
+
+type goConfig struct {
+	metadata map[string]interface{}
+	repanic  bool
+}
+
+func newGoConfig(options []GoOptionFunc) *goConfig {
+	config := &goConfig{
+		metadata: map[string]interface{}{},
+		repanic:  false,
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return config
+}
+
+// helper functions for setting goroutine options
+
+// sets the metadata of the start event of the span
+func WithGoMetadata(metadata map[string]interface{}) GoOptionFunc {
+	return func(config *goConfig) {
+		config.metadata = metadata
+	}
+}
+
+// sets the repanic flag, a recovered panic is panicked again after its event is triggered
+func WithRepanic(repanic bool) GoOptionFunc {
+	return func(config *goConfig) {
+		config.repanic = repanic
+	}
+}
+
+// the error of a goroutine that panicked
+type PanicError struct {
+	Value interface{} // the recovered panic
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+/*
+Runs the func in a goroutine wrapped in a span.
+the span triggers {name}.start, {name}.end and {name}.panic with the same
+measurements and metadata as TriggerSpan. a panic is recovered once its
+event is triggered, unless the repanic flag is set.
+*/
+func Go(t TelemetryInterface, name string, fn func(), options ...GoOptionFunc) {
+	config := newGoConfig(options)
+
+	go func() {
+		runSpan(t, name, config, func() error {
+			fn()
+			return nil
+		})
+	}()
+}
+
+/*
+A group of goroutines wrapped in spans, like errgroup.Group.
+wait returns the first error returned by a func of the group, a recovered
+panic is returned as a *PanicError.
+*/
+type Group struct {
+	provider TelemetryInterface
+	name     string
+	config   *goConfig
+	cancel   context.CancelFunc
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+func NewGroup(t TelemetryInterface, name string, options ...GoOptionFunc) *Group {
+	return &Group{
+		provider: t,
+		name:     name,
+		config:   newGoConfig(options),
+	}
+}
+
+// returns a new group and a context that is canceled when a func of the group fails or wait returns
+func NewGroupWithContext(ctx context.Context, t TelemetryInterface, name string, options ...GoOptionFunc) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	group := NewGroup(t, name, options...)
+	group.cancel = cancel
+
+	return group, ctx
+}
+
+// runs the func in a goroutine wrapped in a {name} span
+func (g *Group) Go(fn func() error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		if err := runSpan(g.provider, g.name, g.config, fn); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				if g.cancel != nil {
+					g.cancel()
+				}
+			})
+		}
+	}()
+}
+
+// waits for every func of the group and returns the first error
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	return g.err
+}
+
+// private methods
+
+/*
+runs the func in a span, the error it returns is added to the end metadata.
+TriggerSpan triggers the panic event and repanics, so the panic is recovered
+here unless the repanic flag is set.
+*/
+func runSpan(t TelemetryInterface, name string, config *goConfig, fn func() error) (err error) {
+	if !config.repanic {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r}
+			}
+		}()
+	}
+
+	_, err = t.TriggerSpan(name, config.metadata, func() (any, error, map[string]interface{}, map[string]interface{}) {
+		err := fn()
+
+		metadata := map[string]interface{}{}
+		if err != nil {
+			metadata["error"] = err.Error()
+		}
+
+		return nil, err, nil, metadata
+	})
+
+	return err
+}
@@ -0,0 +1,109 @@
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestGo(t *testing.T) {
+	t.Run("should wrap the goroutine in a span", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "worker.start", "worker.end")
+
+		done := make(chan struct{})
+		telemetry.Go(provider, "worker", func() {
+			close(done)
+		}, telemetry.WithGoMetadata(map[string]interface{}{"job": "emails"}))
+		<-done
+
+		if !mailer.AssertReceive("worker.start", 1000, func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["job"] == "emails"
+		}) {
+			t.Errorf("should assert received the start event")
+		}
+
+		if !mailer.AssertSpanCompleted("worker", 1000) {
+			t.Errorf("should complete the span")
+		}
+	})
+
+	t.Run("should recover a panic into a panic event", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "worker.start", "worker.panic")
+
+		telemetry.Go(provider, "worker", func() {
+			panic("worker failed")
+		})
+
+		if !mailer.AssertReceive("worker.panic", 1000, func(event string, box ...mailbox.MailData) bool {
+			_, ok := box[0].Metadata["stackTrace"]
+			return box[0].Metadata["error"] == "worker failed" && ok
+		}) {
+			t.Errorf("should assert received the panic event")
+		}
+
+		if !mailer.AssertSpanPanicked("worker", 1000) {
+			t.Errorf("should assert the span panicked")
+		}
+	})
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("should wait for every func and return the first error", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "fetch.end")
+
+		failed := errors.New("fetch failed")
+		group := telemetry.NewGroup(provider, "fetch")
+		group.Go(func() error { return nil })
+		group.Go(func() error { return failed })
+
+		if err := group.Wait(); err != failed {
+			t.Errorf("expected %v, got %v", failed, err)
+		}
+
+		if !mailer.AssertReceived("fetch.end", func(event string, box ...mailbox.MailData) bool {
+			errored := 0
+			for _, mail := range box {
+				if mail.Metadata["error"] == "fetch failed" {
+					errored++
+				}
+			}
+			return len(box) == 2 && errored == 1
+		}) {
+			t.Errorf("should add the error to the end metadata")
+		}
+	})
+
+	t.Run("should return a panic as an error and cancel the context", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "fetch.panic")
+
+		group, ctx := telemetry.NewGroupWithContext(context.Background(), provider, "fetch")
+		group.Go(func() error {
+			panic("fetch failed")
+		})
+		group.Go(func() error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		err := group.Wait()
+
+		var panicErr *telemetry.PanicError
+		if !errors.As(err, &panicErr) || panicErr.Value != "fetch failed" {
+			t.Errorf("expected a panic error, got %v", err)
+		}
+
+		if !mailer.AssertReceived("fetch.panic", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 1
+		}) {
+			t.Errorf("should assert received the panic event")
+		}
+	})
+}
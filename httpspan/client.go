@@ -0,0 +1,150 @@
+package httpspan
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/traceparent"
+)
+
+// the default event the span suffixes are appended to
+const ClientEvent = "http.client.request"
+
+// the header carrying the trace context of a request
+const TraceparentHeader = traceparent.TraceparentHeader
+
+// transport option func
+type TransportOption func(transport *Transport)
+
+/*
+A round tripper that wraps every outbound request in a span.
+the span triggers {event}.start with the method and host, and {event}.end
+with the method, host and status. a failed request has the error and its
+class, one of timeout, canceled, dns, connection, tls or unknown, in the
+end metadata instead of the status.
+the start and end events have the same measurements as the events of
+TriggerSpan, including the duration in milliseconds. the span is a child of
+the span carried by the context of the request.
+*/
+type Transport struct {
+	provider  telemetry.TelemetryInterface
+	base      http.RoundTripper
+	event     string
+	propagate bool
+}
+
+// wraps the base round tripper, http.DefaultTransport if nil
+func NewTransport(provider telemetry.TelemetryInterface, base http.RoundTripper, options ...TransportOption) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport := &Transport{
+		provider: provider,
+		base:     base,
+		event:    ClientEvent,
+	}
+
+	for _, option := range options {
+		option(transport)
+	}
+
+	return transport
+}
+
+// helper functions for setting transport options
+
+// sets the event the span suffixes are appended to
+func WithClientEvent(event string) TransportOption {
+	return func(transport *Transport) {
+		transport.event = event
+	}
+}
+
+// sets the traceparent and tracestate headers of every request to the trace context of its span
+func WithPropagation(propagate bool) TransportOption {
+	return func(transport *Transport) {
+		transport.propagate = propagate
+	}
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, ids := traceparent.Seed(r.Context(), nil)
+
+	if t.propagate {
+		// a round tripper must not modify the request it was given
+		r = r.Clone(ctx)
+		traceparent.Inject(ctx, r.Header)
+	}
+
+	startTime := time.Now().UnixMilli()
+	t.provider.TriggerEvent(t.event+telemetry.SpanStartSuffix, spanMeasurement(ids, map[string]interface{}{
+		"start_time": startTime,
+	}), map[string]interface{}{
+		"method": r.Method,
+		"host":   r.URL.Host,
+	})
+
+	response, err := t.base.RoundTrip(r)
+
+	endTime := time.Now().UnixMilli()
+	metadata := map[string]interface{}{
+		"method": r.Method,
+		"host":   r.URL.Host,
+	}
+	if err != nil {
+		metadata["error"] = err.Error()
+		metadata["error_class"] = errorClass(err)
+	} else {
+		metadata["status"] = response.StatusCode
+	}
+
+	t.provider.TriggerEvent(t.event+telemetry.SpanEndSuffix, spanMeasurement(ids, map[string]interface{}{
+		"duration": endTime - startTime,
+		"end_time": endTime,
+	}), metadata)
+
+	return response, err
+}
+
+// private methods
+
+// adds the span ids seeded by traceparent.Seed to the measurement
+func spanMeasurement(ids map[string]interface{}, measurement map[string]interface{}) map[string]interface{} {
+	for key, value := range ids {
+		measurement[key] = value
+	}
+
+	return measurement
+}
+
+// classifies the error of a failed request
+func errorClass(err error) string {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &authorityErr):
+		return "tls"
+	case errors.As(err, &opErr):
+		return "connection"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,111 @@
+package httpspan_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/httpspan"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Header().Set("X-Traceparent", r.Header.Get(httpspan.TraceparentHeader))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	t.Run("should wrap the request in a span", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, httpspan.ClientEvent+".start", httpspan.ClientEvent+".end")
+
+		client := &http.Client{Transport: httpspan.NewTransport(telemetry, nil)}
+		response, err := client.Post(server.URL+"/users", "text/plain", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response.Body.Close()
+
+		if response.Header.Get("X-Traceparent") != "" {
+			t.Errorf("should not propagate the trace context by default")
+		}
+
+		host := strings.TrimPrefix(server.URL, "http://")
+		if !mailer.AssertReceived(httpspan.ClientEvent+".end", func(event string, box ...mailbox.MailData) bool {
+			_, ok := box[0].Measurement["duration"]
+			return box[0].Metadata["method"] == "POST" &&
+				box[0].Metadata["host"] == host &&
+				box[0].Metadata["status"] == http.StatusCreated && ok
+		}) {
+			t.Errorf("should assert received the end event")
+		}
+
+		if !mailer.AssertSpanCompleted(httpspan.ClientEvent, 0) {
+			t.Errorf("should complete the span")
+		}
+	})
+
+	t.Run("should propagate the trace context", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, httpspan.ClientEvent+".start")
+
+		client := &http.Client{Transport: httpspan.NewTransport(telemetry, nil, httpspan.WithPropagation(true))}
+		request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		response.Body.Close()
+
+		if request.Header.Get(httpspan.TraceparentHeader) != "" {
+			t.Errorf("should not modify the original request")
+		}
+
+		traceparent := response.Header.Get("X-Traceparent")
+		if !mailer.AssertReceived(httpspan.ClientEvent+".start", func(event string, box ...mailbox.MailData) bool {
+			return traceparent == "00-"+box[0].Measurement["trace_id"].(string)+"-"+box[0].Measurement["span_id"].(string)+"-01"
+		}) {
+			t.Errorf("expected the traceparent of the span, got %q", traceparent)
+		}
+	})
+
+	t.Run("should classify failed requests", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, httpspan.ClientEvent+".end")
+
+		client := &http.Client{Transport: httpspan.NewTransport(telemetry, nil)}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/slow", nil)
+		if _, err := client.Do(request); err == nil {
+			t.Fatalf("expected the request to time out")
+		}
+
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		if _, err := client.Get(closed.URL); err == nil {
+			t.Fatalf("expected the request to fail")
+		}
+
+		if !mailer.AssertReceived(httpspan.ClientEvent+".end", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 2 &&
+				box[0].Metadata["error_class"] == "timeout" &&
+				box[1].Metadata["error_class"] == "connection"
+		}) {
+			t.Errorf("should assert received the error classes")
+		}
+	})
+}
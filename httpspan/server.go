@@ -0,0 +1,163 @@
+package httpspan
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/traceparent"
+)
+
+// the default event the span suffixes are appended to
+const ServerEvent = "http.server.request"
+
+// server option func
+type ServerOption func(server *serverConfig)
+
+type serverConfig struct {
+	event string
+	route func(r *http.Request) string
+}
+
+// helper functions for setting server options
+
+// sets the event the span suffixes are appended to
+func WithServerEvent(event string) ServerOption {
+	return func(server *serverConfig) {
+		server.event = event
+	}
+}
+
+/*
+sets the func returning the route of a request.
+the route should have a low cardinality, such as /users/{id}, as it is
+used to group requests. by default it is the pattern matched by http.ServeMux.
+*/
+func WithRoute(route func(r *http.Request) string) ServerOption {
+	return func(server *serverConfig) {
+		server.route = route
+	}
+}
+
+/*
+Returns a middleware that wraps every request in a span.
+the span triggers {event}.start with the method, path and remote address,
+and {event}.end with the method, route, status, bytes and remote address
+as metadata and the bytes written as a measurement. a panic in the handler
+triggers {event}.panic and is repanicked, exactly as TriggerSpan does.
+responses with a 5xx status also trigger {event}.exception with the end
+measurement and metadata.
+the span continues the trace of the traceparent header of the request, and
+the context of the request passed to the handler carries the span.
+*/
+func Middleware(provider telemetry.TelemetryInterface, options ...ServerOption) func(http.Handler) http.Handler {
+	config := &serverConfig{
+		event: ServerEvent,
+		route: func(r *http.Request) string {
+			return r.Pattern
+		},
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			metadata := map[string]interface{}{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"remote_addr": r.RemoteAddr,
+			}
+
+			ctx := traceparent.Extract(r.Context(), r.Header)
+			ctx, metadata = traceparent.Seed(ctx, metadata)
+			r = r.WithContext(ctx)
+
+			var measurement map[string]interface{}
+			var endMetadata map[string]interface{}
+			provider.TriggerSpan(config.event, metadata, func() (any, error, map[string]interface{}, map[string]interface{}) {
+				next.ServeHTTP(recorder, r)
+
+				// the route is read after serving as http.ServeMux sets the pattern while routing
+				endMetadata = map[string]interface{}{
+					"method":      r.Method,
+					"route":       config.route(r),
+					"status":      recorder.status,
+					"bytes":       recorder.bytes,
+					"remote_addr": r.RemoteAddr,
+				}
+				measurement = map[string]interface{}{
+					"bytes": recorder.bytes,
+				}
+
+				return nil, nil, measurement, endMetadata
+			})
+
+			if recorder.status >= http.StatusInternalServerError {
+				// the exception has the ids the span was seeded with
+				exceptionMeasurement := map[string]interface{}{
+					"bytes": measurement["bytes"],
+				}
+				for _, key := range []string{telemetry.SpanIDKey, telemetry.TraceIDKey, telemetry.ParentSpanIDKey} {
+					if id, ok := metadata[key]; ok {
+						exceptionMeasurement[key] = id
+					}
+				}
+				provider.TriggerEvent(config.event+".exception", exceptionMeasurement, endMetadata)
+			}
+		})
+	}
+}
+
+// private methods
+
+// records the status and the bytes written by the handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+
+	return n, err
+}
+
+// flushes the wrapped writer if it supports flushing
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// hijacks the wrapped writer if it supports hijacking
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, errors.New("httpspan: the response writer does not support hijacking")
+}
+
+// lets http.ResponseController reach the wrapped writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,162 @@
+package httpspan_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/httpspan"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id")))
+	})
+	mux.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	})
+
+	t.Run("should wrap the request in a span", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, httpspan.ServerEvent+".start", httpspan.ServerEvent+".end")
+
+		request := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		request.RemoteAddr = "10.0.0.1:5000"
+		recorder := httptest.NewRecorder()
+		httpspan.Middleware(telemetry)(mux).ServeHTTP(recorder, request)
+
+		if recorder.Body.String() != "user 42" {
+			t.Errorf("expected the handler response, got %q", recorder.Body.String())
+		}
+
+		if !mailer.AssertReceived(httpspan.ServerEvent+".start", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["method"] == "GET" && box[0].Metadata["path"] == "/users/42"
+		}) {
+			t.Errorf("should assert received the start event")
+		}
+
+		if !mailer.AssertReceived(httpspan.ServerEvent+".end", func(event string, box ...mailbox.MailData) bool {
+			metadata := box[0].Metadata
+			return metadata["route"] == "GET /users/{id}" &&
+				metadata["status"] == http.StatusOK &&
+				metadata["bytes"] == int64(7) &&
+				metadata["remote_addr"] == "10.0.0.1:5000" &&
+				box[0].Measurement["bytes"] == int64(7)
+		}) {
+			t.Errorf("should assert received the end event")
+		}
+
+		if !mailer.AssertSpanCompleted(httpspan.ServerEvent, 0) {
+			t.Errorf("should complete the span")
+		}
+	})
+
+	t.Run("should trigger an exception for 5xx responses", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, httpspan.ServerEvent+".end", httpspan.ServerEvent+".exception")
+
+		request := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		httpspan.Middleware(telemetry)(mux).ServeHTTP(httptest.NewRecorder(), request)
+
+		if !mailer.AssertReceived(httpspan.ServerEvent+".exception", func(event string, box ...mailbox.MailData) bool {
+			_, ok := box[0].Measurement["span_id"]
+			return box[0].Metadata["status"] == http.StatusServiceUnavailable && ok
+		}) {
+			t.Errorf("should assert received the exception event")
+		}
+	})
+
+	t.Run("should trigger a panic event and repanic", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, httpspan.ServerEvent+".start", httpspan.ServerEvent+".panic")
+
+		func() {
+			defer func() {
+				if r := recover(); r != "handler failed" {
+					t.Errorf("expected the panic to be repanicked, got %v", r)
+				}
+			}()
+
+			request := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			httpspan.Middleware(telemetry)(mux).ServeHTTP(httptest.NewRecorder(), request)
+		}()
+
+		if !mailer.AssertSpanPanicked(httpspan.ServerEvent, 0) {
+			t.Errorf("should assert the span panicked")
+		}
+	})
+
+	t.Run("should use the route func", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "api.request.end")
+
+		middleware := httpspan.Middleware(telemetry,
+			httpspan.WithServerEvent("api.request"),
+			httpspan.WithRoute(func(r *http.Request) string { return "users" }),
+		)
+		request := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		middleware(mux).ServeHTTP(httptest.NewRecorder(), request)
+
+		if !mailer.AssertReceived("api.request.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["route"] == "users"
+		}) {
+			t.Errorf("should assert received the end event with the route")
+		}
+	})
+}
+
+func TestMiddlewarePropagation(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, telemetry, httpspan.ServerEvent+".end", httpspan.ClientEvent+".start")
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(httpspan.TraceparentHeader)))
+	}))
+	defer downstream.Close()
+
+	client := &http.Client{Transport: httpspan.NewTransport(telemetry, nil, httpspan.WithPropagation(true))}
+	handler := httpspan.Middleware(telemetry)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+		response, err := client.Do(request)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer response.Body.Close()
+		io.Copy(w, response.Body)
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(httpspan.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var serverSpanID string
+	if !mailer.AssertReceived(httpspan.ServerEvent+".end", func(event string, box ...mailbox.MailData) bool {
+		serverSpanID, _ = box[0].Measurement["span_id"].(string)
+		return box[0].Measurement["trace_id"] == "4bf92f3577b34da6a3ce929d0e0e4736" &&
+			box[0].Measurement["parent_span_id"] == "00f067aa0ba902b7"
+	}) {
+		t.Errorf("should continue the incoming trace")
+	}
+
+	if !mailer.AssertReceived(httpspan.ClientEvent+".start", func(event string, box ...mailbox.MailData) bool {
+		return box[0].Measurement["parent_span_id"] == serverSpanID &&
+			recorder.Body.String() == "00-4bf92f3577b34da6a3ce929d0e0e4736-"+box[0].Measurement["span_id"].(string)+"-01"
+	}) {
+		t.Errorf("should propagate the trace to the outbound request, got %q", recorder.Body.String())
+	}
+}
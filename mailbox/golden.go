@@ -0,0 +1,154 @@
+package mailbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// the environment variable that regenerates the golden files instead of comparing against them
+const UpdateGoldenEnv = "GOPULSE_UPDATE_GOLDEN"
+
+// value written in place of ignored keys
+const ignoredValue = "<ignored>"
+
+// keys filled in by TriggerSpan that change on every run
+var VolatileKeys = []string{"start_time", "end_time", "duration", "errorTime", "stackTrace", telemetry.SpanIDKey, telemetry.TraceIDKey, telemetry.ParentSpanIDKey}
+
+// golden option func
+type GoldenOption func(options *goldenOptions)
+
+type goldenOptions struct {
+	ignoredKeys map[string]bool
+}
+
+// ignores the keys in the measurements and metadata on top of VolatileKeys
+func WithIgnoredKeys(keys ...string) GoldenOption {
+	return func(options *goldenOptions) {
+		for _, key := range keys {
+			options.ignoredKeys[key] = true
+		}
+	}
+}
+
+// a mail as written to the golden file
+type goldenMail struct {
+	Event       string                 `json:"event"`
+	Measurement map[string]interface{} `json:"measurement"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+/*
+Compares every mail received by the mailer against the golden file.
+the mail is written in the order it was received, the values of ignored
+keys are replaced so only their presence is compared. run the tests with
+GOPULSE_UPDATE_GOLDEN=1 to regenerate the file.
+*/
+func AssertGolden(t testing.TB, path string, mailer *Mailer, options ...GoldenOption) {
+	t.Helper()
+
+	goldenOptions := &goldenOptions{ignoredKeys: make(map[string]bool)}
+	for _, key := range VolatileKeys {
+		goldenOptions.ignoredKeys[key] = true
+	}
+	for _, option := range options {
+		option(goldenOptions)
+	}
+
+	got, err := encodeGolden(mailer.Timeline(), goldenOptions)
+	if err != nil {
+		t.Fatalf("mailbox: failed to encode golden mail: %v", err)
+	}
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mailbox: failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("mailbox: failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("mailbox: failed to read golden file, run with GOPULSE_UPDATE_GOLDEN=1 to create it: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("mailbox: events do not match %s\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// private methods
+
+// encode the timeline as indented json
+func encodeGolden(timeline []Mail, options *goldenOptions) ([]byte, error) {
+	mails := make([]goldenMail, 0, len(timeline))
+	for _, mail := range timeline {
+		mails = append(mails, goldenMail{
+			Event:       mail.Event,
+			Measurement: normalizeGolden(mail.Measurement, options),
+			Metadata:    normalizeGolden(mail.Metadata, options),
+		})
+	}
+
+	// keep the file readable, the values are never embedded in html
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(mails); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// replace ignored keys and values that can not be encoded as json
+func normalizeGolden(values map[string]interface{}, options *goldenOptions) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if options.ignoredKeys[key] {
+			normalized[key] = ignoredValue
+			continue
+		}
+
+		normalized[key] = normalizeGoldenValue(value)
+	}
+
+	return normalized
+}
+
+func normalizeGoldenValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	// keep values json can encode, anything else is replaced by its type
+	// as values such as channels and funcs have no stable representation
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("<%s>", reflect.TypeOf(value))
+	}
+
+	return value
+}
+
+// is the update variable set to a true value?
+func updateGolden() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv))
+	return update
+}
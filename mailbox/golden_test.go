@@ -0,0 +1,100 @@
+package mailbox_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+// emits the events of a user signup
+func signup(provider telemetry.TelemetryInterface) {
+	provider.TriggerSpan("gopulse.signup", map[string]interface{}{"email": "user@example.com"}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+		provider.TriggerEvent("gopulse.signup.validation", map[string]interface{}{
+			"fields": 3,
+		}, map[string]interface{}{
+			"error":   errors.New("email taken"),
+			"retries": make(chan int),
+		})
+
+		return nil, nil, map[string]interface{}{"attempts": 2}, map[string]interface{}{"result": "ok", "request_id": "abc-123"}
+	})
+}
+
+func TestMailerAssertGolden(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should match the golden file", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.signup.validation").BuildSpanHandlers("gopulse.signup")
+
+		signup(telemetry)
+
+		mailbox.AssertGolden(t, filepath.Join("testdata", "signup.golden"), mailer, mailbox.WithIgnoredKeys("request_id"))
+	})
+
+	t.Run("should report a changed event stream", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.signup.validation").BuildSpanHandlers("gopulse.signup")
+
+		signup(telemetry)
+		telemetry.TriggerEvent("gopulse.signup.validation", map[string]interface{}{}, map[string]interface{}{})
+
+		recorder := &failureRecorder{TB: t}
+		mailbox.AssertGolden(recorder, filepath.Join("testdata", "signup.golden"), mailer, mailbox.WithIgnoredKeys("request_id"))
+
+		if !recorder.failed {
+			t.Errorf("should fail when the events do not match the golden file")
+		}
+	})
+
+	t.Run("should fail when the golden file is missing", func(t *testing.T) {
+		t.Setenv(mailbox.UpdateGoldenEnv, "")
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.signup")
+
+		signup(telemetry)
+
+		path := filepath.Join(t.TempDir(), "missing.golden")
+		recorder := &failureRecorder{TB: t}
+		mailbox.AssertGolden(recorder, path, mailer)
+
+		if !recorder.failed {
+			t.Errorf("should fail when the golden file is missing")
+		}
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("should not create the golden file without the update variable")
+		}
+	})
+
+	t.Run("should write the golden file with the update variable", func(t *testing.T) {
+		t.Setenv(mailbox.UpdateGoldenEnv, "1")
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.signup")
+
+		signup(telemetry)
+
+		path := filepath.Join(t.TempDir(), "signup.golden")
+		mailbox.AssertGolden(t, path, mailer)
+
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("should create the golden file: %v", err)
+		}
+	})
+}
+
+// records failures instead of failing the test
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (f *failureRecorder) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func (f *failureRecorder) Fatalf(format string, args ...interface{}) {
+	f.failed = true
+}
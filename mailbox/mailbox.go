@@ -1,9 +1,18 @@
 package mailbox
 
+import "time"
+
 // mailer interface
 type MailData struct {
 	Measurement map[string]interface{}
 	Metadata    map[string]interface{}
+	Sequence    uint64 // order the mail was received in across all events
+}
+
+// mail received for an event
+type Mail struct {
+	Event string
+	MailData
 }
 
 // mailbox func
@@ -16,4 +25,16 @@ type MailboxInterface interface {
 	AssertReceived(event string, mailboxFunc MailboxFunc) bool
 	RefuteReceive(event string, timeout int, mailboxFunc MailboxFunc) bool
 	RefuteReceived(event string, mailboxFunc MailboxFunc) bool
+	// assert a span for the event completes without panicking
+	AssertSpanCompleted(event string, timeout int) bool
+	// assert a span for the event panics
+	AssertSpanPanicked(event string, timeout int) bool
+	// assert every completed span for the event ran for less than the duration
+	AssertSpanDurationBelow(event string, duration time.Duration) bool
+	// assert every started span has ended or panicked
+	AssertNoOrphanSpans() bool
+	// clear every mailbox
+	Reset()
+	// remove and return the mail received for the event
+	Drain(event string) []MailData
 }
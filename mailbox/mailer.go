@@ -1,6 +1,7 @@
 package mailbox
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -8,21 +9,58 @@ import (
 )
 
 type Mailer struct {
-	mailbox  map[string][]MailData
+	mailbox  map[string]*mailRing
 	mu       sync.RWMutex
 	handlers []telemetry.EventRegistrar
 	id       string
+	sequence uint64
+
+	capacity      int            // default number of mails kept per event, zero keeps every mail
+	eventCapacity map[string]int // number of mails kept for specific events
+}
+
+// mailer option func
+type MailerOption func(mailer *Mailer)
+
+// counters of the mail received for an event
+type MailStats struct {
+	Total    uint64 // mails received
+	Retained int    // mails still held in the mailbox
+	Evicted  uint64 // mails dropped to stay within the capacity
 }
 
 // mailer will implement the mailbox interface
 // and the telemetry handler interface
 
-func NewMailer(id string) *Mailer {
-	return &Mailer{
-		mailbox:  make(map[string][]MailData),
-		handlers: make([]telemetry.EventRegistrar, 0),
-		mu:       sync.RWMutex{},
-		id:       id,
+func NewMailer(id string, options ...MailerOption) *Mailer {
+	mailer := &Mailer{
+		mailbox:       make(map[string]*mailRing),
+		handlers:      make([]telemetry.EventRegistrar, 0),
+		mu:            sync.RWMutex{},
+		id:            id,
+		eventCapacity: make(map[string]int),
+	}
+
+	for _, option := range options {
+		option(mailer)
+	}
+
+	return mailer
+}
+
+// helper functions for setting mailer options
+
+// sets the number of mails kept for every event, the oldest mail is evicted first
+func WithCapacity(capacity int) MailerOption {
+	return func(mailer *Mailer) {
+		mailer.capacity = capacity
+	}
+}
+
+// sets the number of mails kept for the event, overriding WithCapacity
+func WithEventCapacity(event string, capacity int) MailerOption {
+	return func(mailer *Mailer) {
+		mailer.eventCapacity[event] = capacity
 	}
 }
 
@@ -63,10 +101,7 @@ func (m *Mailer) AssertReceive(event string, timeout int, mailboxFunc MailboxFun
 			return false
 		default:
 			// check the mailbox
-			m.mu.RLock()
-			mailbox, ok := m.mailbox[event]
-			m.mu.RUnlock()
-
+			mailbox, ok := m.mail(event)
 			if !ok {
 				continue
 			}
@@ -81,10 +116,7 @@ func (m *Mailer) AssertReceive(event string, timeout int, mailboxFunc MailboxFun
 
 func (m *Mailer) AssertReceived(event string, mailboxFunc MailboxFunc) bool {
 	// check if mailbox has received the event
-	m.mu.RLock()
-	mailbox, ok := m.mailbox[event]
-	m.mu.RUnlock()
-
+	mailbox, ok := m.mail(event)
 	if !ok {
 		return false
 	}
@@ -105,6 +137,103 @@ func (m *Mailer) RefuteReceived(event string, mailboxFunc MailboxFunc) bool {
 	return !m.AssertReceived(event, mailboxFunc)
 }
 
+// clears the mail received for every event
+func (m *Mailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mailbox = make(map[string]*mailRing)
+}
+
+// removes and returns the mail received for the event
+func (m *Mailer) Drain(event string) []MailData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mailbox, ok := m.mailbox[event]
+	if !ok {
+		return nil
+	}
+
+	// keep the counters, only the retained mail is removed
+	m.mailbox[event] = &mailRing{
+		items:    make([]MailData, 0),
+		capacity: mailbox.capacity,
+		total:    mailbox.total,
+		evicted:  mailbox.evicted,
+	}
+
+	return mailbox.mail()
+}
+
+// returns the mail retained for every event in the order it was received
+func (m *Mailer) Timeline() []Mail {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	timeline := make([]Mail, 0)
+	for event, mailbox := range m.mailbox {
+		for _, data := range mailbox.mail() {
+			timeline = append(timeline, Mail{Event: event, MailData: data})
+		}
+	}
+
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Sequence < timeline[j].Sequence
+	})
+
+	return timeline
+}
+
+// returns the counters of the mail received for the event
+func (m *Mailer) Stats(event string) MailStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mailbox, ok := m.mailbox[event]
+	if !ok {
+		return MailStats{}
+	}
+
+	return MailStats{
+		Total:    mailbox.total,
+		Retained: mailbox.len(),
+		Evicted:  mailbox.evicted,
+	}
+}
+
+// returns a copy of the mail retained for the event
+func (m *Mailer) mail(event string) ([]MailData, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mailbox, ok := m.mailbox[event]
+	if !ok || mailbox.len() == 0 {
+		return nil, false
+	}
+
+	return mailbox.mail(), true
+}
+
+// returns the number of mails kept for the event
+func (m *Mailer) capacityFor(event string) int {
+	if capacity, ok := m.eventCapacity[event]; ok {
+		return capacity
+	}
+
+	return m.capacity
+}
+
+// returns a copy of the mail retained for the event, the caller must hold the lock
+func (m *Mailer) retained(event string) []MailData {
+	mailbox, ok := m.mailbox[event]
+	if !ok {
+		return nil
+	}
+
+	return mailbox.mail()
+}
+
 // add a new handler to the mailer
 func (m *Mailer) registerHandler(event string) telemetry.EventRegistrar {
 	return telemetry.EventRegistrar{
@@ -121,16 +250,16 @@ func (m *Mailer) buildHandler() telemetry.HandleEventFunc {
 
 		mailbox, ok := m.mailbox[event]
 		if !ok {
-			mailbox = make([]MailData, 0)
+			mailbox = newMailRing(m.capacityFor(event))
+			m.mailbox[event] = mailbox
 		}
 
 		// add the event to the mailbox
-		mailbox = append(mailbox, MailData{
+		m.sequence++
+		mailbox.push(MailData{
 			Measurement: measurement,
 			Metadata:    metadata,
+			Sequence:    m.sequence,
 		})
-
-		// update the mailbox
-		m.mailbox[event] = mailbox
 	}
 }
@@ -14,15 +14,12 @@ func TestMailerAssertReceive(t *testing.T) {
 	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
 
 	t.Run("should assert receive", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.test",
 			"gopulse.event.test2",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// lets trigger in a a go routine
 		go func() {
 			time.Sleep(100 * time.Millisecond)
@@ -50,15 +47,12 @@ func TestMailerAssertReceive(t *testing.T) {
 	})
 
 	t.Run("timeout exceeded - no event received", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.telemetry.test",
 			"gopulse.telemetry.test2",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// create a goroutine to trigger a different event
 		go func() {
 			time.Sleep(100 * time.Millisecond)
@@ -84,15 +78,12 @@ func TestMailerAssertReceived(t *testing.T) {
 	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
 
 	t.Run("should assert received after event triggered", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.received",
 			"gopulse.event.received2",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger the event first
 		telemetry.TriggerEvent("gopulse.event.received", map[string]interface{}{
 			"occured_at": time.Now().UnixMilli(),
@@ -119,15 +110,12 @@ func TestMailerAssertReceived(t *testing.T) {
 	})
 
 	t.Run("should not assert received for non-triggered event", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.notreceived",
 			"gopulse.event.notreceived2",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger a different event
 		telemetry.TriggerEvent("gopulse.event.notreceived2", map[string]interface{}{
 			"occured_at": time.Now().UnixMilli(),
@@ -148,14 +136,11 @@ func TestMailerAssertReceived(t *testing.T) {
 	})
 
 	t.Run("should assert received with custom validation", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.validation",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger multiple events with different data
 		telemetry.TriggerEvent("gopulse.event.validation", map[string]interface{}{
 			"count": 5,
@@ -207,15 +192,12 @@ func TestMailerRefuteReceive(t *testing.T) {
 	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
 
 	t.Run("should refute receive when event not triggered", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute",
 			"gopulse.event.refute2",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger a different event
 		go func() {
 			time.Sleep(100 * time.Millisecond)
@@ -236,14 +218,11 @@ func TestMailerRefuteReceive(t *testing.T) {
 	})
 
 	t.Run("should refute receive when validation fails", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute.validation",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger the event but with wrong data
 		go func() {
 			time.Sleep(100 * time.Millisecond)
@@ -270,14 +249,11 @@ func TestMailerRefuteReceive(t *testing.T) {
 	})
 
 	t.Run("should not refute receive when event triggered and validation passes", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute.pass",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger the event with correct data
 		go func() {
 			time.Sleep(100 * time.Millisecond)
@@ -308,15 +284,12 @@ func TestMailerRefuteReceived(t *testing.T) {
 	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
 
 	t.Run("should refute received when event not triggered", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute.received",
 			"gopulse.event.refute.received2",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger a different event
 		telemetry.TriggerEvent("gopulse.event.refute.received2", map[string]interface{}{
 			"occured_at": time.Now().UnixMilli(),
@@ -337,14 +310,11 @@ func TestMailerRefuteReceived(t *testing.T) {
 	})
 
 	t.Run("should refute received when validation fails", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute.validation",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger the event with wrong data
 		telemetry.TriggerEvent("gopulse.event.refute.validation", map[string]interface{}{
 			"count": 5,
@@ -369,14 +339,11 @@ func TestMailerRefuteReceived(t *testing.T) {
 	})
 
 	t.Run("should not refute received when event triggered and validation passes", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute.success",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// trigger the event with correct data
 		telemetry.TriggerEvent("gopulse.event.refute.success", map[string]interface{}{
 			"count": 10,
@@ -404,14 +371,11 @@ func TestMailerRefuteReceived(t *testing.T) {
 	})
 
 	t.Run("should refute received with empty mailbox", func(t *testing.T) {
-		// register the telemetry event
-		mailer := mailbox.NewMailer("test").BuildHandlers(
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
 			"gopulse.event.refute.empty",
 		)
 
-		// register the telemetry event
-		telemetry.AddHandlers(mailer)
-
 		// don't trigger any events
 
 		// refute received should return true (empty mailbox)
@@ -422,3 +386,211 @@ func TestMailerRefuteReceived(t *testing.T) {
 		}
 	})
 }
+
+func TestMailerReset(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should clear every mailbox", func(t *testing.T) {
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
+			"gopulse.event.reset",
+			"gopulse.event.reset2",
+		)
+
+		telemetry.TriggerEvent("gopulse.event.reset", map[string]interface{}{}, map[string]interface{}{})
+		telemetry.TriggerEvent("gopulse.event.reset2", map[string]interface{}{}, map[string]interface{}{})
+
+		mailer.Reset()
+
+		// both mailboxes should be empty after the reset
+		for _, event := range []string{"gopulse.event.reset", "gopulse.event.reset2"} {
+			if !mailer.RefuteReceived(event, func(event string, box ...mailbox.MailData) bool {
+				return len(box) > 0
+			}) {
+				t.Errorf("should refute received %s after reset", event)
+			}
+		}
+
+		// the mailer keeps listening after a reset
+		telemetry.TriggerEvent("gopulse.event.reset", map[string]interface{}{}, map[string]interface{}{
+			"result": "after reset",
+		})
+
+		if !mailer.AssertReceived("gopulse.event.reset", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 1 && box[0].Metadata["result"] == "after reset"
+		}) {
+			t.Errorf("should assert received after reset")
+		}
+	})
+}
+
+func TestMailerDrain(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should remove and return the mail for the event", func(t *testing.T) {
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry,
+			"gopulse.event.drain",
+			"gopulse.event.drain2",
+		)
+
+		telemetry.TriggerEvent("gopulse.event.drain", map[string]interface{}{"count": 1}, map[string]interface{}{})
+		telemetry.TriggerEvent("gopulse.event.drain", map[string]interface{}{"count": 2}, map[string]interface{}{})
+		telemetry.TriggerEvent("gopulse.event.drain2", map[string]interface{}{"count": 3}, map[string]interface{}{})
+
+		drained := mailer.Drain("gopulse.event.drain")
+		if len(drained) != 2 {
+			t.Fatalf("expected 2 drained mails, got %d", len(drained))
+		}
+
+		if drained[0].Measurement["count"] != 1 || drained[1].Measurement["count"] != 2 {
+			t.Errorf("drained mails should keep their order, got %v", drained)
+		}
+
+		// the drained event should be empty
+		if mailer.AssertReceived("gopulse.event.drain", func(event string, box ...mailbox.MailData) bool {
+			return true
+		}) {
+			t.Errorf("should not assert received after drain")
+		}
+
+		// other events are left alone
+		if !mailer.AssertReceived("gopulse.event.drain2", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 1
+		}) {
+			t.Errorf("should assert received on the event that was not drained")
+		}
+	})
+
+	t.Run("should return nothing for an empty mailbox", func(t *testing.T) {
+		// attach a mailer scoped to this subtest
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.event.drain.empty")
+
+		if drained := mailer.Drain("gopulse.event.drain.empty"); len(drained) != 0 {
+			t.Errorf("expected no drained mails, got %d", len(drained))
+		}
+	})
+}
+
+func TestMailerForTest(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var first *mailbox.Mailer
+
+	t.Run("first subtest", func(t *testing.T) {
+		first = mailbox.ForTest(t, telemetry, "gopulse.event.scoped")
+		second := mailbox.ForTest(t, telemetry, "gopulse.event.scoped")
+
+		if first.ID() == second.ID() {
+			t.Fatalf("mailers should have unique ids, got %s", first.ID())
+		}
+
+		telemetry.TriggerEvent("gopulse.event.scoped", map[string]interface{}{}, map[string]interface{}{})
+
+		// both mailers should be attached
+		for _, mailer := range []*mailbox.Mailer{first, second} {
+			if !mailer.AssertReceived("gopulse.event.scoped", func(event string, box ...mailbox.MailData) bool {
+				return len(box) == 1
+			}) {
+				t.Errorf("mailer %s should assert received", mailer.ID())
+			}
+		}
+	})
+
+	// the first subtest has finished so its mailer should be detached
+	telemetry.TriggerEvent("gopulse.event.scoped", map[string]interface{}{}, map[string]interface{}{})
+
+	if !first.AssertReceived("gopulse.event.scoped", func(event string, box ...mailbox.MailData) bool {
+		return len(box) == 1
+	}) {
+		t.Errorf("mailer should be removed from the provider after its test finishes")
+	}
+}
+
+func TestMailerCapacity(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	// trigger the event with an increasing count
+	trigger := func(event string, count int) {
+		for i := 1; i <= count; i++ {
+			telemetry.TriggerEvent(event, map[string]interface{}{"count": i}, map[string]interface{}{})
+		}
+	}
+
+	t.Run("should evict the oldest mail", func(t *testing.T) {
+		mailer := mailbox.NewMailer(t.Name(), mailbox.WithCapacity(3)).BuildHandlers("gopulse.event.capacity")
+		telemetry.AddHandlers(mailer)
+		defer telemetry.RemoveHandlers(mailer)
+
+		trigger("gopulse.event.capacity", 10)
+
+		if !mailer.AssertReceived("gopulse.event.capacity", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 3 &&
+				box[0].Measurement["count"] == 8 &&
+				box[1].Measurement["count"] == 9 &&
+				box[2].Measurement["count"] == 10
+		}) {
+			t.Errorf("should retain the newest mails in order")
+		}
+
+		stats := mailer.Stats("gopulse.event.capacity")
+		if stats.Total != 10 || stats.Retained != 3 || stats.Evicted != 7 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("should override the capacity per event", func(t *testing.T) {
+		mailer := mailbox.NewMailer(t.Name(),
+			mailbox.WithCapacity(2),
+			mailbox.WithEventCapacity("gopulse.event.capacity.wide", 5),
+		).BuildHandlers("gopulse.event.capacity.narrow", "gopulse.event.capacity.wide")
+		telemetry.AddHandlers(mailer)
+		defer telemetry.RemoveHandlers(mailer)
+
+		trigger("gopulse.event.capacity.narrow", 6)
+		trigger("gopulse.event.capacity.wide", 6)
+
+		if retained := mailer.Stats("gopulse.event.capacity.narrow").Retained; retained != 2 {
+			t.Errorf("expected 2 retained mails, got %d", retained)
+		}
+
+		if retained := mailer.Stats("gopulse.event.capacity.wide").Retained; retained != 5 {
+			t.Errorf("expected 5 retained mails, got %d", retained)
+		}
+	})
+
+	t.Run("should keep every mail without a capacity", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry, "gopulse.event.capacity.unbounded")
+
+		trigger("gopulse.event.capacity.unbounded", 100)
+
+		stats := mailer.Stats("gopulse.event.capacity.unbounded")
+		if stats.Total != 100 || stats.Retained != 100 || stats.Evicted != 0 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("should keep the total when drained", func(t *testing.T) {
+		mailer := mailbox.NewMailer(t.Name(), mailbox.WithCapacity(2)).BuildHandlers("gopulse.event.capacity.drain")
+		telemetry.AddHandlers(mailer)
+		defer telemetry.RemoveHandlers(mailer)
+
+		trigger("gopulse.event.capacity.drain", 4)
+
+		drained := mailer.Drain("gopulse.event.capacity.drain")
+		if len(drained) != 2 || drained[0].Measurement["count"] != 3 {
+			t.Errorf("should drain the retained mails, got %v", drained)
+		}
+
+		trigger("gopulse.event.capacity.drain", 3)
+
+		stats := mailer.Stats("gopulse.event.capacity.drain")
+		if stats.Total != 7 || stats.Retained != 2 || stats.Evicted != 3 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+}
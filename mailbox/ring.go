@@ -0,0 +1,46 @@
+package mailbox
+
+// ring buffer holding the mail received for an event
+// a capacity of zero keeps every mail
+type mailRing struct {
+	items    []MailData
+	head     int // index of the oldest mail once the ring is full
+	capacity int
+	total    uint64 // mail received since the ring was created
+	evicted  uint64 // mail overwritten to stay within the capacity
+}
+
+func newMailRing(capacity int) *mailRing {
+	return &mailRing{
+		items:    make([]MailData, 0),
+		capacity: capacity,
+	}
+}
+
+// add the mail, evicting the oldest one if the ring is full
+func (r *mailRing) push(data MailData) {
+	r.total++
+
+	if r.capacity <= 0 || len(r.items) < r.capacity {
+		r.items = append(r.items, data)
+		return
+	}
+
+	r.evicted++
+	r.items[r.head] = data
+	r.head = (r.head + 1) % r.capacity
+}
+
+// returns a copy of the retained mail, oldest first
+func (r *mailRing) mail() []MailData {
+	mail := make([]MailData, 0, len(r.items))
+	mail = append(mail, r.items[r.head:]...)
+	mail = append(mail, r.items[:r.head]...)
+
+	return mail
+}
+
+// returns the number of retained mails
+func (r *mailRing) len() int {
+	return len(r.items)
+}
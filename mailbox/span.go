@@ -0,0 +1,193 @@
+package mailbox
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// a span rebuilt from the events emitted by TriggerSpan
+type Span struct {
+	Event string    // the base event of the span
+	Start MailData  // the {event}.start mail
+	End   *MailData // the {event}.end mail, nil if the span has not ended
+	Panic *MailData // the {event}.panic mail, nil if the span has not panicked
+}
+
+// reports whether the span ended without panicking
+func (s Span) Completed() bool {
+	return s.End != nil
+}
+
+// reports whether the span panicked
+func (s Span) Panicked() bool {
+	return s.Panic != nil
+}
+
+// reports whether the span has neither ended nor panicked
+func (s Span) Orphaned() bool {
+	return s.End == nil && s.Panic == nil
+}
+
+// returns the duration measured by TriggerSpan, zero if the span has not ended
+func (s Span) Duration() time.Duration {
+	if s.End == nil {
+		return 0
+	}
+
+	duration, ok := s.End.Measurement["duration"].(int64)
+	if !ok {
+		return 0
+	}
+
+	return time.Duration(duration) * time.Millisecond
+}
+
+// registers the start, end and panic events of the spans
+func (m *Mailer) BuildSpanHandlers(events ...string) *Mailer {
+	spanEvents := make([]string, 0, len(events)*3)
+	for _, event := range events {
+		spanEvents = append(spanEvents, event+telemetry.SpanStartSuffix, event+telemetry.SpanEndSuffix, event+telemetry.SpanPanicSuffix)
+	}
+
+	return m.BuildHandlers(spanEvents...)
+}
+
+/*
+Returns the spans received for the event.
+starts are paired with the end or panic sharing their span id. mail
+without a span id is paired in the order it completed, which is only
+reliable while none of the span events have been evicted.
+*/
+func (m *Mailer) Spans(event string) []Span {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.spans(event)
+}
+
+func (m *Mailer) AssertSpanCompleted(event string, timeout int) bool {
+	return m.awaitSpan(event, timeout, Span.Completed)
+}
+
+func (m *Mailer) AssertSpanPanicked(event string, timeout int) bool {
+	return m.awaitSpan(event, timeout, Span.Panicked)
+}
+
+func (m *Mailer) AssertSpanDurationBelow(event string, duration time.Duration) bool {
+	completed := 0
+	for _, span := range m.Spans(event) {
+		if !span.Completed() {
+			continue
+		}
+
+		if span.Duration() >= duration {
+			return false
+		}
+		completed++
+	}
+
+	return completed > 0
+}
+
+func (m *Mailer) AssertNoOrphanSpans() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for event := range m.mailbox {
+		if !strings.HasSuffix(event, telemetry.SpanStartSuffix) {
+			continue
+		}
+
+		for _, span := range m.spans(strings.TrimSuffix(event, telemetry.SpanStartSuffix)) {
+			if span.Orphaned() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// private methods
+
+// wait for a span of the event that satisfies the check
+func (m *Mailer) awaitSpan(event string, timeout int, check func(Span) bool) bool {
+	// create a timer channel
+	timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+	defer timer.Stop()
+
+	for {
+		for _, span := range m.Spans(event) {
+			if check(span) {
+				return true
+			}
+		}
+
+		select {
+		case <-timer.C:
+			// timeout while waiting for the span
+			return false
+		default:
+		}
+	}
+}
+
+// pair the start events with their end or panic events, the caller must hold the lock
+func (m *Mailer) spans(event string) []Span {
+	starts := m.retained(event + telemetry.SpanStartSuffix)
+	ends := m.retained(event + telemetry.SpanEndSuffix)
+	panics := m.retained(event + telemetry.SpanPanicSuffix)
+
+	// order the completions as they were received
+	type completion struct {
+		data     MailData
+		panicked bool
+	}
+	completions := make([]completion, 0, len(ends)+len(panics))
+	for _, data := range ends {
+		completions = append(completions, completion{data: data})
+	}
+	for _, data := range panics {
+		completions = append(completions, completion{data: data, panicked: true})
+	}
+	sort.Slice(completions, func(i, j int) bool {
+		return completions[i].data.Sequence < completions[j].data.Sequence
+	})
+
+	// completions carrying a span id are paired by id, the rest in order
+	byID := make(map[string]completion)
+	unidentified := make([]completion, 0)
+	for _, completion := range completions {
+		if id, ok := telemetry.SpanID(completion.data.Measurement); ok {
+			byID[id] = completion
+		} else {
+			unidentified = append(unidentified, completion)
+		}
+	}
+
+	spans := make([]Span, len(starts))
+	for i, start := range starts {
+		spans[i] = Span{Event: event, Start: start}
+
+		spanID, identified := telemetry.SpanID(start.Measurement)
+		completion, ok := byID[spanID]
+		if !identified || !ok {
+			if len(unidentified) == 0 {
+				continue
+			}
+			completion, unidentified = unidentified[0], unidentified[1:]
+		}
+
+		data := completion.data
+		if completion.panicked {
+			spans[i].Panic = &data
+		} else {
+			spans[i].End = &data
+		}
+	}
+
+	return spans
+}
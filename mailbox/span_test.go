@@ -0,0 +1,169 @@
+package mailbox_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+// span func that sleeps before returning
+func sleepingSpan(delay time.Duration) telemetry.SpanFunc[any] {
+	return func() (any, error, map[string]interface{}, map[string]interface{}) {
+		time.Sleep(delay)
+		return nil, nil, map[string]interface{}{}, map[string]interface{}{"result": "ok"}
+	}
+}
+
+// trigger a span that panics and swallow the repropagated panic
+func triggerPanickingSpan(provider telemetry.TelemetryInterface, event string) {
+	defer func() {
+		recover()
+	}()
+
+	provider.TriggerSpan(event, map[string]interface{}{}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+		panic(errors.New("span panic"))
+	})
+}
+
+func TestMailerSpans(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should pair starts with their completions", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.pair")
+
+		telemetry.TriggerSpan("gopulse.span.pair", map[string]interface{}{"call": 1}, sleepingSpan(0))
+		triggerPanickingSpan(telemetry, "gopulse.span.pair")
+		telemetry.TriggerSpan("gopulse.span.pair", map[string]interface{}{"call": 3}, sleepingSpan(0))
+
+		spans := mailer.Spans("gopulse.span.pair")
+		if len(spans) != 3 {
+			t.Fatalf("expected 3 spans, got %d", len(spans))
+		}
+
+		if !spans[0].Completed() || spans[0].Start.Metadata["call"] != 1 {
+			t.Errorf("first span should have completed, got %+v", spans[0])
+		}
+
+		if !spans[1].Panicked() || spans[1].Panic.Metadata["stackTrace"] == nil {
+			t.Errorf("second span should have panicked, got %+v", spans[1])
+		}
+
+		if !spans[2].Completed() || spans[2].Start.Metadata["call"] != 3 {
+			t.Errorf("third span should have completed, got %+v", spans[2])
+		}
+	})
+
+	t.Run("should report a span without a completion as orphaned", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.orphan")
+
+		telemetry.TriggerEvent("gopulse.span.orphan.start", map[string]interface{}{
+			"start_time": time.Now().UnixMilli(),
+		}, map[string]interface{}{})
+
+		if mailer.AssertNoOrphanSpans() {
+			t.Errorf("should not assert no orphan spans")
+		}
+
+		spans := mailer.Spans("gopulse.span.orphan")
+		if len(spans) != 1 || !spans[0].Orphaned() {
+			t.Errorf("expected a single orphaned span, got %+v", spans)
+		}
+	})
+}
+
+func TestMailerAssertSpanCompleted(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should assert span completed", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.completed")
+
+		go telemetry.TriggerSpan("gopulse.span.completed", map[string]interface{}{}, sleepingSpan(50*time.Millisecond))
+
+		if !mailer.AssertSpanCompleted("gopulse.span.completed", 1000) {
+			t.Errorf("should assert span completed")
+		}
+
+		if !mailer.AssertNoOrphanSpans() {
+			t.Errorf("should assert no orphan spans")
+		}
+	})
+
+	t.Run("should not assert span completed when it panics", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.completed.panic")
+
+		triggerPanickingSpan(telemetry, "gopulse.span.completed.panic")
+
+		if mailer.AssertSpanCompleted("gopulse.span.completed.panic", 100) {
+			t.Errorf("should not assert span completed")
+		}
+	})
+}
+
+func TestMailerAssertSpanPanicked(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should assert span panicked", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.panicked")
+
+		go triggerPanickingSpan(telemetry, "gopulse.span.panicked")
+
+		if !mailer.AssertSpanPanicked("gopulse.span.panicked", 1000) {
+			t.Errorf("should assert span panicked")
+		}
+
+		if !mailer.AssertNoOrphanSpans() {
+			t.Errorf("a panicked span should not be orphaned")
+		}
+	})
+
+	t.Run("should not assert span panicked when it completes", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.panicked.ok")
+
+		telemetry.TriggerSpan("gopulse.span.panicked.ok", map[string]interface{}{}, sleepingSpan(0))
+
+		if mailer.AssertSpanPanicked("gopulse.span.panicked.ok", 100) {
+			t.Errorf("should not assert span panicked")
+		}
+	})
+}
+
+func TestMailerAssertSpanDurationBelow(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should assert duration below", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.fast")
+
+		telemetry.TriggerSpan("gopulse.span.fast", map[string]interface{}{}, sleepingSpan(0))
+
+		if !mailer.AssertSpanDurationBelow("gopulse.span.fast", time.Second) {
+			t.Errorf("should assert span duration below")
+		}
+	})
+
+	t.Run("should not assert duration below for a slow span", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.slow")
+
+		telemetry.TriggerSpan("gopulse.span.slow", map[string]interface{}{}, sleepingSpan(0))
+		telemetry.TriggerSpan("gopulse.span.slow", map[string]interface{}{}, sleepingSpan(60*time.Millisecond))
+
+		if mailer.AssertSpanDurationBelow("gopulse.span.slow", 50*time.Millisecond) {
+			t.Errorf("should not assert span duration below")
+		}
+	})
+
+	t.Run("should not assert duration below without completed spans", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry).BuildSpanHandlers("gopulse.span.none")
+
+		if mailer.AssertSpanDurationBelow("gopulse.span.none", time.Second) {
+			t.Errorf("should not assert span duration below without spans")
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package mailbox
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// sequence used to keep the ids of test mailers unique
+var testMailerSeq atomic.Uint64
+
+/*
+Creates a mailer listening for the events and attaches it to the provider.
+the mailer gets a unique id so mailers from other tests sharing the provider
+are not replaced, and it is removed from the provider when the test finishes.
+*/
+func ForTest(t testing.TB, provider telemetry.TelemetryInterface, events ...string) *Mailer {
+	t.Helper()
+
+	id := fmt.Sprintf("mailbox.test.%s.%d", t.Name(), testMailerSeq.Add(1))
+	mailer := NewMailer(id).BuildHandlers(events...)
+
+	if err := provider.AddHandlers(mailer); err != nil {
+		t.Fatalf("mailbox: failed to attach mailer %s: %v", id, err)
+	}
+
+	t.Cleanup(func() {
+		provider.RemoveHandlers(mailer)
+	})
+
+	return mailer
+}
@@ -0,0 +1,285 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// the aggregate of a metric for a set of tag values
+type Series struct {
+	Metric  Metric
+	Tags    map[string]string
+	Count   uint64    // number of measurements
+	Sum     float64   // sum of the measurements
+	Last    float64   // last measurement
+	Min     float64   // smallest measurement
+	Max     float64   // largest measurement
+	Buckets []uint64  // measurements per bucket, the last one counts values above every bound
+	Updated time.Time // when the series was last updated
+}
+
+// a handler that aggregates metrics over the events it receives
+type Handler struct {
+	id      string
+	metrics []Metric
+	series  map[string]*Series
+	mu      sync.RWMutex
+}
+
+// metrics handler will implement the telemetry handler interface
+
+func NewHandler(id string, metrics ...Metric) *Handler {
+	return &Handler{
+		id:      id,
+		metrics: metrics,
+		series:  make(map[string]*Series),
+		mu:      sync.RWMutex{},
+	}
+}
+
+func (h *Handler) ID() string {
+	return h.id
+}
+
+func (h *Handler) Config() interface{} {
+	return nil
+}
+
+func (h *Handler) AttachedHandlers() []telemetry.EventRegistrar {
+	return Registrars(h.handleEvent, h.metrics...)
+}
+
+// returns the metric definitions of the handler
+func (h *Handler) Metrics() []Metric {
+	return h.metrics
+}
+
+// returns a copy of every series, ordered by metric name and tags
+func (h *Handler) Snapshot() []Series {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	snapshot := make([]Series, 0, len(keys))
+	for _, key := range keys {
+		series := *h.series[key]
+		series.Tags = copyTags(series.Tags)
+		series.Buckets = append([]uint64(nil), series.Buckets...)
+		snapshot = append(snapshot, series)
+	}
+
+	return snapshot
+}
+
+// clears every series
+func (h *Handler) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.series = make(map[string]*Series)
+}
+
+/*
+Returns a registrar of the handler for every event of the metrics.
+each event is registered once, so metrics sharing an event are handled
+together by a single call of the handler.
+*/
+func Registrars(handler telemetry.HandleEventFunc, metrics ...Metric) []telemetry.EventRegistrar {
+	registrars := make([]telemetry.EventRegistrar, 0)
+	registered := make(map[string]bool)
+
+	for _, metric := range metrics {
+		if registered[metric.Event] {
+			continue
+		}
+		registered[metric.Event] = true
+
+		registrars = append(registrars, telemetry.EventRegistrar{
+			Event:   metric.Event,
+			Handler: handler,
+		})
+	}
+
+	return registrars
+}
+
+// private methods
+
+func (h *Handler) handleEvent(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for _, metric := range h.metrics {
+		if metric.Event != event {
+			continue
+		}
+
+		value, ok := metricValue(metric, measurement)
+		if !ok {
+			continue
+		}
+
+		tags := tagValues(metric.Tags, metadata)
+		key := seriesKey(metric, tags)
+
+		series, ok := h.series[key]
+		if !ok {
+			series = &Series{
+				Metric: metric,
+				Tags:   tags,
+				Min:    math.Inf(1),
+				Max:    math.Inf(-1),
+			}
+			if metric.Kind == KindDistribution {
+				series.Buckets = make([]uint64, len(metric.Buckets)+1)
+			}
+			h.series[key] = series
+		}
+
+		series.observe(value, now)
+	}
+}
+
+// record the value on the series
+func (s *Series) observe(value float64, now time.Time) {
+	s.Count++
+	s.Sum += value
+	s.Last = value
+	s.Min = math.Min(s.Min, value)
+	s.Max = math.Max(s.Max, value)
+	s.Updated = now
+
+	if s.Metric.Kind == KindDistribution {
+		s.Buckets[sort.SearchFloat64s(s.Metric.Buckets, value)]++
+	}
+}
+
+// returns the value of the measurement the metric aggregates
+func metricValue(metric Metric, measurement map[string]interface{}) (float64, bool) {
+	// counters without a measurement count every event
+	if metric.Kind == KindCounter && metric.Measurement == "" {
+		return 1, true
+	}
+
+	value, ok := measurement[metric.Measurement]
+	if !ok {
+		return 0, false
+	}
+
+	number, ok := ToFloat(value)
+	if !ok {
+		return 0, false
+	}
+
+	// counters count the events carrying the measurement
+	if metric.Kind == KindCounter {
+		return 1, true
+	}
+
+	return number, true
+}
+
+/*
+Converts a measurement to a float.
+supports every numeric type and time.Duration, which is converted to milliseconds
+to match the durations measured by TriggerSpan. NaN and infinite floats are
+rejected, as one of them would make every later sum non finite, which json
+and the statsd line format can not encode.
+*/
+func ToFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return float64(v) / float64(time.Millisecond), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return finite(float64(v))
+	case float64:
+		return finite(v)
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// returns the tag values read from the metadata
+func tagValues(tags []string, metadata map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		value, ok := metadata[tag]
+		if !ok || value == nil {
+			values[tag] = ""
+			continue
+		}
+
+		values[tag] = fmt.Sprint(value)
+	}
+
+	return values
+}
+
+// returns the key identifying the series of the metric for the tag values
+func seriesKey(metric Metric, tags map[string]string) string {
+	var key strings.Builder
+	key.WriteString(metric.Name)
+	key.WriteByte(0)
+	key.WriteString(metric.Kind.String())
+	for _, tag := range metric.Tags {
+		key.WriteByte(0)
+		key.WriteString(tags[tag])
+	}
+
+	return key.String()
+}
+
+func copyTags(tags map[string]string) map[string]string {
+	copied := make(map[string]string, len(tags))
+	for key, value := range tags {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// returns the value if it is neither NaN nor infinite
+func finite(value float64) (float64, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+
+	return value, true
+}
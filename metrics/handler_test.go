@@ -0,0 +1,226 @@
+package metrics_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+// returns the series of the metric with the tag value
+func findSeries(snapshot []metrics.Series, name string, tags map[string]string) *metrics.Series {
+	for i, series := range snapshot {
+		if series.Metric.Name != name {
+			continue
+		}
+
+		matched := true
+		for key, value := range tags {
+			if series.Tags[key] != value {
+				matched = false
+			}
+		}
+
+		if matched {
+			return &snapshot[i]
+		}
+	}
+
+	return nil
+}
+
+func TestMetricDefinitions(t *testing.T) {
+	t.Run("should default the name to the event and measurement", func(t *testing.T) {
+		if name := metrics.Sum("db.query.end", "rows").Name; name != "db.query.end.rows" {
+			t.Errorf("unexpected name %s", name)
+		}
+
+		if name := metrics.Counter("db.query.end", "").Name; name != "db.query.end" {
+			t.Errorf("unexpected name %s", name)
+		}
+
+		if name := metrics.Counter("db.query.end", "", metrics.WithName("db.query.count")).Name; name != "db.query.count" {
+			t.Errorf("unexpected name %s", name)
+		}
+	})
+
+	t.Run("should use the default buckets for distributions", func(t *testing.T) {
+		metric := metrics.Distribution("db.query.end", "duration")
+		if len(metric.Buckets) != len(metrics.DefaultBuckets) {
+			t.Errorf("expected the default buckets, got %v", metric.Buckets)
+		}
+
+		metric = metrics.Distribution("db.query.end", "duration", metrics.WithBuckets(100, 10, 50))
+		if len(metric.Buckets) != 3 || metric.Buckets[0] != 10 || metric.Buckets[2] != 100 {
+			t.Errorf("expected sorted buckets, got %v", metric.Buckets)
+		}
+	})
+}
+
+func TestRegistrars(t *testing.T) {
+	handler := func(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+	}
+
+	registrars := metrics.Registrars(handler,
+		metrics.Counter("db.query.end", ""),
+		metrics.Sum("db.query.end", "rows"),
+		metrics.LastValue("vm.goroutines", "count"),
+	)
+
+	// metrics sharing an event are registered once
+	if len(registrars) != 2 || registrars[0].Event != "db.query.end" || registrars[1].Event != "vm.goroutines" {
+		t.Errorf("expected a registrar per event, got %+v", registrars)
+	}
+}
+
+func TestHandlerAggregates(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	handler := metrics.NewHandler("metrics",
+		metrics.Counter("db.query.end", "", metrics.WithName("db.query.count"), metrics.WithTags("table")),
+		metrics.Sum("db.query.end", "rows", metrics.WithTags("table")),
+		metrics.LastValue("vm.memory", "total"),
+		metrics.Summary("db.query.end", "duration"),
+		metrics.Distribution("db.query.end", "duration", metrics.WithName("db.query.duration.histogram"), metrics.WithBuckets(10, 100)),
+	)
+	telemetry.AddHandlers(handler)
+
+	queries := []struct {
+		table    string
+		rows     int
+		duration int64
+	}{
+		{"users", 1, 5},
+		{"users", 3, 50},
+		{"orders", 10, 500},
+	}
+	for _, query := range queries {
+		telemetry.TriggerEvent("db.query.end", map[string]interface{}{
+			"rows":     query.rows,
+			"duration": query.duration,
+		}, map[string]interface{}{
+			"table": query.table,
+		})
+	}
+
+	telemetry.TriggerEvent("vm.memory", map[string]interface{}{"total": 100}, map[string]interface{}{})
+	telemetry.TriggerEvent("vm.memory", map[string]interface{}{"total": 250}, map[string]interface{}{})
+
+	snapshot := handler.Snapshot()
+
+	t.Run("should count events per tag", func(t *testing.T) {
+		users := findSeries(snapshot, "db.query.count", map[string]string{"table": "users"})
+		orders := findSeries(snapshot, "db.query.count", map[string]string{"table": "orders"})
+
+		if users == nil || users.Sum != 2 {
+			t.Errorf("expected 2 users queries, got %+v", users)
+		}
+
+		if orders == nil || orders.Sum != 1 {
+			t.Errorf("expected 1 orders query, got %+v", orders)
+		}
+	})
+
+	t.Run("should sum the measurement per tag", func(t *testing.T) {
+		users := findSeries(snapshot, "db.query.end.rows", map[string]string{"table": "users"})
+		if users == nil || users.Sum != 4 {
+			t.Errorf("expected 4 users rows, got %+v", users)
+		}
+	})
+
+	t.Run("should keep the last value", func(t *testing.T) {
+		memory := findSeries(snapshot, "vm.memory.total", nil)
+		if memory == nil || memory.Last != 250 {
+			t.Errorf("expected the last value to be 250, got %+v", memory)
+		}
+	})
+
+	t.Run("should summarize the measurement", func(t *testing.T) {
+		duration := findSeries(snapshot, "db.query.end.duration", nil)
+		if duration == nil {
+			t.Fatal("expected a duration summary")
+		}
+
+		if duration.Count != 3 || duration.Sum != 555 || duration.Min != 5 || duration.Max != 500 {
+			t.Errorf("unexpected summary %+v", duration)
+		}
+	})
+
+	t.Run("should bucket the distribution", func(t *testing.T) {
+		histogram := findSeries(snapshot, "db.query.duration.histogram", nil)
+		if histogram == nil {
+			t.Fatal("expected a duration distribution")
+		}
+
+		// 5 <= 10, 50 <= 100 and 500 above every bound
+		if len(histogram.Buckets) != 3 || histogram.Buckets[0] != 1 || histogram.Buckets[1] != 1 || histogram.Buckets[2] != 1 {
+			t.Errorf("unexpected buckets %v", histogram.Buckets)
+		}
+	})
+
+	t.Run("should clear the series on reset", func(t *testing.T) {
+		handler.Reset()
+
+		if len(handler.Snapshot()) != 0 {
+			t.Errorf("expected no series after reset")
+		}
+	})
+}
+
+func TestHandlerSkipsInvalidMeasurements(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	handler := metrics.NewHandler("metrics", metrics.Sum("cache.lookup", "size"))
+	telemetry.AddHandlers(handler)
+
+	telemetry.TriggerEvent("cache.lookup", map[string]interface{}{"size": "large"}, map[string]interface{}{})
+	telemetry.TriggerEvent("cache.lookup", map[string]interface{}{}, map[string]interface{}{})
+	telemetry.TriggerEvent("cache.lookup", map[string]interface{}{"size": 2 * time.Millisecond}, map[string]interface{}{})
+	telemetry.TriggerEvent("cache.lookup", map[string]interface{}{"size": math.NaN()}, map[string]interface{}{})
+	telemetry.TriggerEvent("cache.lookup", map[string]interface{}{"size": math.Inf(1)}, map[string]interface{}{})
+	telemetry.TriggerEvent("cache.lookup", map[string]interface{}{"size": float32(math.Inf(-1))}, map[string]interface{}{})
+
+	snapshot := handler.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Count != 1 || snapshot[0].Sum != 2 {
+		t.Errorf("expected only the numeric measurement to be aggregated, got %+v", snapshot)
+	}
+}
+
+func TestHandlerConcurrentEvents(t *testing.T) {
+	// get workers to handle the events
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig(
+		telemetry.WithAllowConcurrentExecution(true),
+		telemetry.WithConcurrentPoolSize(4),
+		telemetry.WithConcurrentBufferSize(1000),
+	))
+
+	handler := metrics.NewHandler("metrics", metrics.Counter("jobs.done", ""))
+	telemetry.AddHandlers(handler)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				telemetry.TriggerEvent("jobs.done", map[string]interface{}{}, map[string]interface{}{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := telemetry.(*providers.TelemetryProvider).WaitIdle(t.Context(), 0); err != nil {
+		t.Fatalf("telemetry should become idle: %v", err)
+	}
+
+	snapshot := handler.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Sum != 500 {
+		t.Errorf("expected 500 jobs counted, got %+v", snapshot)
+	}
+}
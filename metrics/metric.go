@@ -0,0 +1,137 @@
+package metrics
+
+import "sort"
+
+// the kind of aggregation a metric keeps
+type Kind int
+
+const (
+	KindCounter      Kind = iota // number of events
+	KindSum                      // sum of the measurement
+	KindLastValue                // last value of the measurement
+	KindSummary                  // count, sum, min and max of the measurement
+	KindDistribution             // histogram of the measurement over buckets
+)
+
+// returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindSum:
+		return "sum"
+	case KindLastValue:
+		return "last_value"
+	case KindSummary:
+		return "summary"
+	case KindDistribution:
+		return "distribution"
+	default:
+		return "unknown"
+	}
+}
+
+// metric option func
+type MetricOption func(metric *Metric)
+
+// definition of a metric aggregated over an event
+type Metric struct {
+	Name        string    // name of the metric, defaults to {event}.{measurement}
+	Kind        Kind      // the aggregation kept for the metric
+	Event       string    // the event the metric listens for
+	Measurement string    // the measurement key aggregated, counters may leave it empty
+	Tags        []string  // metadata keys the aggregates are split by
+	Buckets     []float64 // upper bounds of the distribution buckets
+	Description string    // description for reporters
+	Unit        string    // unit of the measurement for reporters
+}
+
+// default upper bounds of the distribution buckets
+var DefaultBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// counts the events
+func Counter(event string, measurement string, options ...MetricOption) Metric {
+	return newMetric(KindCounter, event, measurement, options...)
+}
+
+// sums the measurement
+func Sum(event string, measurement string, options ...MetricOption) Metric {
+	return newMetric(KindSum, event, measurement, options...)
+}
+
+// keeps the last value of the measurement
+func LastValue(event string, measurement string, options ...MetricOption) Metric {
+	return newMetric(KindLastValue, event, measurement, options...)
+}
+
+// keeps the count, sum, min and max of the measurement
+func Summary(event string, measurement string, options ...MetricOption) Metric {
+	return newMetric(KindSummary, event, measurement, options...)
+}
+
+// keeps a histogram of the measurement, DefaultBuckets are used unless WithBuckets is set
+func Distribution(event string, measurement string, options ...MetricOption) Metric {
+	return newMetric(KindDistribution, event, measurement, append([]MetricOption{WithBuckets(DefaultBuckets...)}, options...)...)
+}
+
+// helper functions for setting metric options
+
+// sets the name of the metric
+func WithName(name string) MetricOption {
+	return func(metric *Metric) {
+		metric.Name = name
+	}
+}
+
+// sets the metadata keys the aggregates are split by
+func WithTags(tags ...string) MetricOption {
+	return func(metric *Metric) {
+		metric.Tags = tags
+	}
+}
+
+// sets the upper bounds of the distribution buckets
+func WithBuckets(buckets ...float64) MetricOption {
+	return func(metric *Metric) {
+		sorted := append([]float64{}, buckets...)
+		sort.Float64s(sorted)
+		metric.Buckets = sorted
+	}
+}
+
+// sets the description of the metric
+func WithDescription(description string) MetricOption {
+	return func(metric *Metric) {
+		metric.Description = description
+	}
+}
+
+// sets the unit of the measurement
+func WithUnit(unit string) MetricOption {
+	return func(metric *Metric) {
+		metric.Unit = unit
+	}
+}
+
+// private methods
+
+func newMetric(kind Kind, event string, measurement string, options ...MetricOption) Metric {
+	metric := Metric{
+		Kind:        kind,
+		Event:       event,
+		Measurement: measurement,
+	}
+
+	for _, option := range options {
+		option(&metric)
+	}
+
+	if metric.Name == "" {
+		metric.Name = event
+		if measurement != "" {
+			metric.Name = event + "." + measurement
+		}
+	}
+
+	return metric
+}
@@ -0,0 +1,137 @@
+package otlp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/trexreigns/gopulse/metrics"
+)
+
+// config update func
+type ConfigUpdateFunc func(config *Config)
+
+// configs that are passed to the otlp exporter
+type Config struct {
+	Endpoint       string            // base url of the collector, the signal paths are appended to it
+	Headers        map[string]string // headers sent with every request
+	ServiceName    string            // the service.name resource attribute
+	Spans          []string          // base events of the spans exported as traces
+	Metrics        []metrics.Metric  // metrics aggregated and exported
+	BatchSize      int               // the number of spans sent in a request
+	MaxQueueSize   int               // the number of finished spans held before new ones are dropped
+	FlushInterval  time.Duration     // how often the spans and metrics are exported
+	MaxRetries     int               // the number of retries of a failed request
+	InitialBackoff time.Duration     // the wait before the first retry, doubled on every retry
+	MaxBackoff     time.Duration     // the longest wait between retries
+	Client         *http.Client      // the client used to send requests
+}
+
+/*
+Registers a new otlp config.
+if no configs are provided, the default sets
+endpoint to http://localhost:4318,
+serviceName to gopulse,
+batchSize to 512,
+maxQueueSize to 2048,
+flushInterval to 5s,
+maxRetries to 3,
+initialBackoff to 100ms,
+maxBackoff to 5s,
+client to a client with a 10s timeout
+*/
+func NewConfig(configs ...ConfigUpdateFunc) *Config {
+	otlpConfig := &Config{
+		Endpoint:       "http://localhost:4318",
+		Headers:        map[string]string{},
+		ServiceName:    "gopulse",
+		Spans:          []string{},
+		Metrics:        []metrics.Metric{},
+		BatchSize:      512,
+		MaxQueueSize:   2048,
+		FlushInterval:  5 * time.Second,
+		MaxRetries:     3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Client:         &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, config := range configs {
+		config(otlpConfig)
+	}
+
+	return otlpConfig
+}
+
+// helper functions for setting otlp config
+
+// sets the base url of the collector
+func WithEndpoint(endpoint string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Endpoint = endpoint
+	}
+}
+
+// sets a header sent with every request
+func WithHeader(key string, value string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Headers[key] = value
+	}
+}
+
+// sets the service.name resource attribute
+func WithServiceName(serviceName string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.ServiceName = serviceName
+	}
+}
+
+// adds the base events of spans exported as traces
+func WithSpans(events ...string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Spans = append(config.Spans, events...)
+	}
+}
+
+// adds metrics aggregated and exported
+func WithMetrics(definitions ...metrics.Metric) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Metrics = append(config.Metrics, definitions...)
+	}
+}
+
+// sets the number of spans sent in a request
+func WithBatchSize(batchSize int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.BatchSize = batchSize
+	}
+}
+
+// sets the number of finished spans held before new ones are dropped
+func WithMaxQueueSize(maxQueueSize int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxQueueSize = maxQueueSize
+	}
+}
+
+// sets how often the spans and metrics are exported
+func WithFlushInterval(flushInterval time.Duration) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.FlushInterval = flushInterval
+	}
+}
+
+// sets the retries of a failed request and the backoff between them
+func WithRetry(maxRetries int, initialBackoff time.Duration, maxBackoff time.Duration) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxRetries = maxRetries
+		config.InitialBackoff = initialBackoff
+		config.MaxBackoff = maxBackoff
+	}
+}
+
+// sets the client used to send requests
+func WithClient(client *http.Client) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Client = client
+	}
+}
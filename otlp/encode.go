@@ -0,0 +1,352 @@
+package otlp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/trexreigns/gopulse/metrics"
+	"github.com/trexreigns/gopulse/spans"
+)
+
+// the otlp/http json encoding of traces and metrics
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+// name of the instrumentation scope
+const scopeName = "github.com/trexreigns/gopulse"
+
+// span kind internal, spans are not tagged with a kind
+const spanKindInternal = 1
+
+// span status codes
+const (
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// metric aggregation temporality cumulative, the aggregates are never reset
+const temporalityCumulative = 2
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+// traces
+
+type tracesRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []keyValue  `json:"attributes"`
+	Events            []spanEvent `json:"events,omitempty"`
+	Status            spanStatus  `json:"status"`
+}
+
+type spanEvent struct {
+	Name         string     `json:"name"`
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Attributes   []keyValue `json:"attributes"`
+}
+
+type spanStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// metrics
+
+type metricsRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type scopeMetrics struct {
+	Scope   scope        `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Unit        string         `json:"unit,omitempty"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Gauge       *otlpGauge     `json:"gauge,omitempty"`
+	Summary     *otlpSummary   `json:"summary,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+}
+
+type otlpSum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type otlpGauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type otlpSummary struct {
+	DataPoints []summaryDataPoint `json:"dataPoints"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+type summaryDataPoint struct {
+	Attributes        []keyValue      `json:"attributes"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	Count             string          `json:"count"`
+	Sum               float64         `json:"sum"`
+	QuantileValues    []quantileValue `json:"quantileValues"`
+}
+
+type quantileValue struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+	Min               float64    `json:"min"`
+	Max               float64    `json:"max"`
+}
+
+// private methods
+
+func serviceResource(serviceName string) resource {
+	return resource{Attributes: []keyValue{stringAttribute("service.name", serviceName)}}
+}
+
+func encodeTraces(serviceName string, finished []spans.Span) tracesRequest {
+	encoded := make([]otlpSpan, 0, len(finished))
+	for _, span := range finished {
+		encoded = append(encoded, encodeSpan(span))
+	}
+
+	return tracesRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource:   serviceResource(serviceName),
+			ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: encoded}},
+		}},
+	}
+}
+
+func encodeSpan(span spans.Span) otlpSpan {
+	encoded := otlpSpan{
+		TraceID:           span.TraceID,
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentSpanID,
+		Name:              span.Name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: unixNano(span.Start),
+		EndTimeUnixNano:   unixNano(span.End),
+		Attributes:        attributes(span.Metadata()),
+		Status:            spanStatus{Code: statusCodeOK},
+	}
+
+	if span.Panicked {
+		message := fmt.Sprint(span.Error)
+		encoded.Status = spanStatus{Code: statusCodeError, Message: message}
+		encoded.Events = []spanEvent{{
+			Name:         "exception",
+			TimeUnixNano: unixNano(span.End),
+			Attributes: []keyValue{
+				stringAttribute("exception.message", message),
+				stringAttribute("exception.stacktrace", span.StackTrace),
+			},
+		}}
+	}
+
+	return encoded
+}
+
+func encodeMetrics(serviceName string, snapshot []metrics.Series, startTime time.Time, now time.Time) metricsRequest {
+	// group the series of each metric into a single otlp metric
+	encoded := make([]otlpMetric, 0)
+	index := make(map[string]int)
+
+	for _, series := range snapshot {
+		key := series.Metric.Name + "\x00" + series.Metric.Kind.String()
+		i, ok := index[key]
+		if !ok {
+			i = len(encoded)
+			index[key] = i
+			encoded = append(encoded, newMetric(series.Metric))
+		}
+
+		addDataPoint(&encoded[i], series, unixNano(startTime), unixNano(now))
+	}
+
+	return metricsRequest{
+		ResourceMetrics: []resourceMetrics{{
+			Resource:     serviceResource(serviceName),
+			ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: encoded}},
+		}},
+	}
+}
+
+func newMetric(metric metrics.Metric) otlpMetric {
+	encoded := otlpMetric{
+		Name:        metric.Name,
+		Description: metric.Description,
+		Unit:        metric.Unit,
+	}
+
+	switch metric.Kind {
+	case metrics.KindCounter, metrics.KindSum:
+		encoded.Sum = &otlpSum{AggregationTemporality: temporalityCumulative, IsMonotonic: metric.Kind == metrics.KindCounter}
+	case metrics.KindLastValue:
+		encoded.Gauge = &otlpGauge{}
+	case metrics.KindSummary:
+		encoded.Summary = &otlpSummary{}
+	case metrics.KindDistribution:
+		encoded.Histogram = &otlpHistogram{AggregationTemporality: temporalityCumulative}
+	}
+
+	return encoded
+}
+
+func addDataPoint(metric *otlpMetric, series metrics.Series, startTime string, now string) {
+	tags := make(map[string]interface{}, len(series.Tags))
+	for key, value := range series.Tags {
+		tags[key] = value
+	}
+	labels := attributes(tags)
+
+	switch {
+	case metric.Sum != nil:
+		metric.Sum.DataPoints = append(metric.Sum.DataPoints, numberDataPoint{labels, startTime, now, series.Sum})
+	case metric.Gauge != nil:
+		metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, numberDataPoint{labels, startTime, now, series.Last})
+	case metric.Summary != nil:
+		metric.Summary.DataPoints = append(metric.Summary.DataPoints, summaryDataPoint{
+			Attributes:        labels,
+			StartTimeUnixNano: startTime,
+			TimeUnixNano:      now,
+			Count:             strconv.FormatUint(series.Count, 10),
+			Sum:               series.Sum,
+			QuantileValues:    []quantileValue{{0, series.Min}, {1, series.Max}},
+		})
+	case metric.Histogram != nil:
+		buckets := make([]string, len(series.Buckets))
+		for i, count := range series.Buckets {
+			buckets[i] = strconv.FormatUint(count, 10)
+		}
+
+		metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, histogramDataPoint{
+			Attributes:        labels,
+			StartTimeUnixNano: startTime,
+			TimeUnixNano:      now,
+			Count:             strconv.FormatUint(series.Count, 10),
+			Sum:               series.Sum,
+			BucketCounts:      buckets,
+			ExplicitBounds:    series.Metric.Buckets,
+			Min:               series.Min,
+			Max:               series.Max,
+		})
+	}
+}
+
+// converts the metadata to attributes ordered by key, nil values are skipped
+func attributes(metadata map[string]interface{}) []keyValue {
+	keys := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	encoded := make([]keyValue, 0, len(keys))
+	for _, key := range keys {
+		encoded = append(encoded, keyValue{Key: key, Value: attributeValue(metadata[key])})
+	}
+
+	return encoded
+}
+
+func attributeValue(value interface{}) anyValue {
+	switch v := value.(type) {
+	case string:
+		return anyValue{StringValue: &v}
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		integer := fmt.Sprint(v)
+		return anyValue{IntValue: &integer}
+	case float32, float64:
+		// NaN and infinities can not be encoded as json numbers, so they fall through to their string form
+		if double, ok := metrics.ToFloat(v); ok {
+			return anyValue{DoubleValue: &double}
+		}
+	case error:
+		message := v.Error()
+		return anyValue{StringValue: &message}
+	}
+
+	formatted := fmt.Sprint(value)
+	return anyValue{StringValue: &formatted}
+}
+
+func stringAttribute(key string, value string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: &value}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,175 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+	"github.com/trexreigns/gopulse/spans"
+)
+
+// paths of the signals appended to the endpoint
+const (
+	tracesPath  = "/v1/traces"
+	metricsPath = "/v1/metrics"
+)
+
+/*
+An exporter that sends spans and metrics to an opentelemetry collector
+using otlp/http with the json encoding.
+spans are paired from the start, end and panic events of TriggerSpan and
+sent in batches. metrics are aggregated by a metrics handler and sent on
+every flush interval. failed requests are retried with an exponential
+backoff.
+*/
+type Exporter struct {
+	id      string
+	config  *Config
+	tracker *spans.Tracker
+	batcher *spans.Batcher
+	metrics *metrics.Handler
+	started time.Time
+}
+
+// errors returned by NewExporter when the config is invalid
+var (
+	ErrInvalidBatchSize     = errors.New("otlp: the batch size must be positive")
+	ErrInvalidMaxQueueSize  = errors.New("otlp: the max queue size must be positive")
+	ErrInvalidFlushInterval = errors.New("otlp: the flush interval must be positive")
+)
+
+// exporter will implement the telemetry handler interface
+
+func NewExporter(id string, config *Config) (*Exporter, error) {
+	if config.BatchSize <= 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	if config.MaxQueueSize <= 0 {
+		return nil, ErrInvalidMaxQueueSize
+	}
+	if config.FlushInterval <= 0 {
+		return nil, ErrInvalidFlushInterval
+	}
+
+	exporter := &Exporter{
+		id:      id,
+		config:  config,
+		metrics: metrics.NewHandler(id, config.Metrics...),
+		started: time.Now(),
+	}
+	exporter.batcher = spans.NewBatcher(exporter.exportSpans,
+		spans.WithBatchSize(config.BatchSize),
+		spans.WithMaxQueueSize(config.MaxQueueSize),
+		spans.WithFlushInterval(config.FlushInterval),
+		spans.WithFlushFunc(exporter.exportMetrics),
+	)
+	exporter.tracker = spans.NewTracker(exporter.batcher.Add, config.Spans...)
+
+	return exporter, nil
+}
+
+func (e *Exporter) ID() string {
+	return e.id
+}
+
+func (e *Exporter) Config() interface{} {
+	return e.config
+}
+
+func (e *Exporter) AttachedHandlers() []telemetry.EventRegistrar {
+	return append(e.tracker.Handlers(), e.metrics.AttachedHandlers()...)
+}
+
+// returns the number of spans dropped
+func (e *Exporter) Dropped() uint64 {
+	return e.batcher.Dropped()
+}
+
+// exports the queued spans and the metrics
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.batcher.Flush(ctx)
+}
+
+// stops the export loop and exports everything still queued
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.batcher.Shutdown(ctx)
+}
+
+// private methods
+
+func (e *Exporter) exportSpans(ctx context.Context, batch []spans.Span) error {
+	return e.post(ctx, tracesPath, encodeTraces(e.config.ServiceName, batch))
+}
+
+func (e *Exporter) exportMetrics(ctx context.Context) error {
+	snapshot := e.metrics.Snapshot()
+	if len(snapshot) == 0 {
+		return nil
+	}
+
+	return e.post(ctx, metricsPath, encodeMetrics(e.config.ServiceName, snapshot, e.started, time.Now()))
+}
+
+// post the payload, retrying with an exponential backoff
+func (e *Exporter) post(ctx context.Context, path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := e.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := e.send(ctx, path, body)
+		if err == nil || !retry || attempt >= e.config.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, e.config.MaxBackoff)
+	}
+}
+
+// send the body, returns whether a failed request can be retried
+func (e *Exporter) send(ctx context.Context, path string, body []byte) (bool, error) {
+	url := strings.TrimSuffix(e.config.Endpoint, "/") + path
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := e.config.Client.Do(request)
+	if err != nil {
+		// the request may succeed once the collector is reachable
+		return ctx.Err() == nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("otlp: %s responded with %s", url, response.Status)
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, err
+	default:
+		return false, err
+	}
+}
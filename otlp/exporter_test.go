@@ -0,0 +1,332 @@
+package otlp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+	"github.com/trexreigns/gopulse/otlp"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+// a collector stand in recording the requests it receives
+type collector struct {
+	mu       sync.Mutex
+	requests map[string][]map[string]interface{}
+	statuses []int // statuses returned before responding with 200
+	server   *httptest.Server
+}
+
+func newCollector(t *testing.T, statuses ...int) *collector {
+	c := &collector{
+		requests: make(map[string][]map[string]interface{}),
+		statuses: statuses,
+	}
+
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("collector received invalid json: %v", err)
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.requests[r.URL.Path] = append(c.requests[r.URL.Path], payload)
+
+		if len(c.statuses) > 0 {
+			status := c.statuses[0]
+			c.statuses = c.statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+	}))
+	t.Cleanup(c.server.Close)
+
+	return c
+}
+
+func (c *collector) received(path string) []map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]map[string]interface{}{}, c.requests[path]...)
+}
+
+// returns the spans of every traces request
+func (c *collector) spans() []map[string]interface{} {
+	spans := make([]map[string]interface{}, 0)
+	for _, request := range c.received("/v1/traces") {
+		for _, resourceSpans := range request["resourceSpans"].([]interface{}) {
+			for _, scopeSpans := range resourceSpans.(map[string]interface{})["scopeSpans"].([]interface{}) {
+				for _, span := range scopeSpans.(map[string]interface{})["spans"].([]interface{}) {
+					spans = append(spans, span.(map[string]interface{}))
+				}
+			}
+		}
+	}
+
+	return spans
+}
+
+func newExporter(t *testing.T, config *otlp.Config) *otlp.Exporter {
+	exporter, err := otlp.NewExporter("otlp", config)
+	if err != nil {
+		t.Fatalf("failed to create the exporter: %v", err)
+	}
+
+	return exporter
+}
+
+func okSpan() (any, error, map[string]interface{}, map[string]interface{}) {
+	return nil, nil, map[string]interface{}{}, map[string]interface{}{"result": "ok"}
+}
+
+func TestExporterSpans(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	collector := newCollector(t)
+
+	exporter := newExporter(t, otlp.NewConfig(
+		otlp.WithEndpoint(collector.server.URL),
+		otlp.WithServiceName("checkout"),
+		otlp.WithSpans("gopulse.checkout"),
+		otlp.WithFlushInterval(time.Hour),
+	))
+	telemetry.AddHandlers(exporter)
+
+	telemetry.TriggerSpan("gopulse.checkout", map[string]interface{}{"user": "42", "items": 3, "ratio": 0.5, "score": math.NaN(), "limit": float32(math.Inf(-1))}, okSpan)
+	func() {
+		defer func() {
+			recover()
+		}()
+
+		telemetry.TriggerSpan("gopulse.checkout", map[string]interface{}{}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			panic(errors.New("card declined"))
+		})
+	}()
+
+	// shutdown flushes the queued spans
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+
+	requests := collector.received("/v1/traces")
+	if len(requests) != 1 {
+		t.Fatalf("expected a single traces request, got %d", len(requests))
+	}
+
+	resource := requests[0]["resourceSpans"].([]interface{})[0].(map[string]interface{})["resource"].(map[string]interface{})
+	attribute := resource["attributes"].([]interface{})[0].(map[string]interface{})
+	if attribute["key"] != "service.name" || attribute["value"].(map[string]interface{})["stringValue"] != "checkout" {
+		t.Errorf("unexpected resource %v", resource)
+	}
+
+	spans := collector.spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	ok := spans[0]
+	if ok["name"] != "gopulse.checkout" || len(ok["traceId"].(string)) != 32 || len(ok["spanId"].(string)) != 16 {
+		t.Errorf("unexpected span %v", ok)
+	}
+
+	if ok["status"].(map[string]interface{})["code"] != float64(1) {
+		t.Errorf("expected an ok status, got %v", ok["status"])
+	}
+
+	attributes := map[string]interface{}{}
+	for _, attribute := range ok["attributes"].([]interface{}) {
+		attribute := attribute.(map[string]interface{})
+		attributes[attribute["key"].(string)] = attribute["value"]
+	}
+	if attributes["user"].(map[string]interface{})["stringValue"] != "42" || attributes["items"].(map[string]interface{})["intValue"] != "3" {
+		t.Errorf("unexpected attributes %v", attributes)
+	}
+	if attributes["ratio"].(map[string]interface{})["doubleValue"] != 0.5 {
+		t.Errorf("expected a double attribute, got %v", attributes["ratio"])
+	}
+
+	// values that are not finite are sent as strings instead of a made up number
+	if attributes["score"].(map[string]interface{})["stringValue"] != "NaN" || attributes["limit"].(map[string]interface{})["stringValue"] != "-Inf" {
+		t.Errorf("expected string attributes, got %v and %v", attributes["score"], attributes["limit"])
+	}
+
+	panicked := spans[1]
+	status := panicked["status"].(map[string]interface{})
+	if status["code"] != float64(2) || status["message"] != "card declined" {
+		t.Errorf("expected an error status, got %v", status)
+	}
+
+	events := panicked["events"].([]interface{})
+	if len(events) != 1 || events[0].(map[string]interface{})["name"] != "exception" {
+		t.Errorf("expected an exception event, got %v", events)
+	}
+}
+
+func TestExporterBatches(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	collector := newCollector(t)
+
+	exporter := newExporter(t, otlp.NewConfig(
+		otlp.WithEndpoint(collector.server.URL),
+		otlp.WithSpans("gopulse.job"),
+		otlp.WithBatchSize(5),
+		otlp.WithFlushInterval(time.Hour),
+	))
+	telemetry.AddHandlers(exporter)
+	defer exporter.Shutdown(context.Background())
+
+	for i := 0; i < 5; i++ {
+		telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, okSpan)
+	}
+
+	// a full batch is exported without waiting for the flush interval
+	deadline := time.Now().Add(time.Second)
+	for len(collector.spans()) < 5 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if len(collector.received("/v1/traces")) != 1 || len(collector.spans()) != 5 {
+		t.Errorf("expected a single batch of 5 spans, got %d spans", len(collector.spans()))
+	}
+}
+
+func TestExporterRejectsInvalidConfig(t *testing.T) {
+	invalid := map[error]otlp.ConfigUpdateFunc{
+		otlp.ErrInvalidBatchSize:     otlp.WithBatchSize(0),
+		otlp.ErrInvalidMaxQueueSize:  otlp.WithMaxQueueSize(-1),
+		otlp.ErrInvalidFlushInterval: otlp.WithFlushInterval(0),
+	}
+
+	for expected, config := range invalid {
+		if _, err := otlp.NewExporter("otlp", otlp.NewConfig(config)); !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+}
+
+func TestExporterRetries(t *testing.T) {
+	t.Run("should retry retryable statuses", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		collector := newCollector(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+		exporter := newExporter(t, otlp.NewConfig(
+			otlp.WithEndpoint(collector.server.URL),
+			otlp.WithSpans("gopulse.job"),
+			otlp.WithFlushInterval(time.Hour),
+			otlp.WithRetry(3, time.Millisecond, 5*time.Millisecond),
+		))
+		telemetry.AddHandlers(exporter)
+
+		telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, okSpan)
+
+		if err := exporter.Shutdown(context.Background()); err != nil {
+			t.Fatalf("expected the export to succeed after retrying, got %v", err)
+		}
+
+		if requests := len(collector.received("/v1/traces")); requests != 3 {
+			t.Errorf("expected 3 attempts, got %d", requests)
+		}
+
+		if exporter.Dropped() != 0 {
+			t.Errorf("expected no dropped spans, got %d", exporter.Dropped())
+		}
+	})
+
+	t.Run("should drop the batch on a permanent failure", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		collector := newCollector(t, http.StatusBadRequest)
+
+		exporter := newExporter(t, otlp.NewConfig(
+			otlp.WithEndpoint(collector.server.URL),
+			otlp.WithSpans("gopulse.job"),
+			otlp.WithFlushInterval(time.Hour),
+			otlp.WithRetry(3, time.Millisecond, 5*time.Millisecond),
+		))
+		telemetry.AddHandlers(exporter)
+
+		telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, okSpan)
+
+		if err := exporter.Shutdown(context.Background()); err == nil {
+			t.Fatal("expected the export to fail")
+		}
+
+		if requests := len(collector.received("/v1/traces")); requests != 1 {
+			t.Errorf("expected a single attempt, got %d", requests)
+		}
+
+		if exporter.Dropped() != 1 {
+			t.Errorf("expected 1 dropped span, got %d", exporter.Dropped())
+		}
+	})
+}
+
+func TestExporterMetrics(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	collector := newCollector(t)
+
+	exporter := newExporter(t, otlp.NewConfig(
+		otlp.WithEndpoint(collector.server.URL),
+		otlp.WithMetrics(
+			metrics.Counter("http.request", "", metrics.WithTags("method")),
+			metrics.Distribution("http.request", "duration", metrics.WithBuckets(10, 100)),
+		),
+		otlp.WithFlushInterval(time.Hour),
+	))
+	telemetry.AddHandlers(exporter)
+
+	telemetry.TriggerEvent("http.request", map[string]interface{}{"duration": 42}, map[string]interface{}{"method": "GET"})
+
+	// a non finite measurement is skipped instead of breaking the encoding
+	telemetry.TriggerEvent("http.request", map[string]interface{}{"duration": math.NaN()}, map[string]interface{}{"method": "GET"})
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+
+	requests := collector.received("/v1/metrics")
+	if len(requests) != 1 {
+		t.Fatalf("expected a single metrics request, got %d", len(requests))
+	}
+
+	scopeMetrics := requests[0]["resourceMetrics"].([]interface{})[0].(map[string]interface{})["scopeMetrics"].([]interface{})[0]
+	exported := scopeMetrics.(map[string]interface{})["metrics"].([]interface{})
+	if len(exported) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(exported))
+	}
+
+	for _, metric := range exported {
+		metric := metric.(map[string]interface{})
+		switch metric["name"] {
+		case "http.request":
+			point := metric["sum"].(map[string]interface{})["dataPoints"].([]interface{})[0].(map[string]interface{})
+			if point["asDouble"] != float64(2) {
+				t.Errorf("unexpected counter data point %v", point)
+			}
+		case "http.request.duration":
+			point := metric["histogram"].(map[string]interface{})["dataPoints"].([]interface{})[0].(map[string]interface{})
+			if point["count"] != "1" || point["sum"] != float64(42) {
+				t.Errorf("unexpected histogram data point %v", point)
+			}
+		default:
+			t.Errorf("unexpected metric %v", metric["name"])
+		}
+	}
+}
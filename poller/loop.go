@@ -0,0 +1,89 @@
+package poller
+
+import (
+	"log"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// runs a poll func on an interval until stopped
+type pollLoop struct {
+	name     string
+	interval time.Duration
+	jitter   time.Duration
+	poll     func()
+
+	done      chan struct{}
+	stopped   sync.WaitGroup
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func newPollLoop(name string, interval time.Duration, poll func()) *pollLoop {
+	return &pollLoop{
+		name:     name,
+		interval: interval,
+		poll:     poll,
+		done:     make(chan struct{}),
+	}
+}
+
+// starting twice is a no-op, a stopped loop can not be started again
+func (l *pollLoop) start() {
+	l.startOnce.Do(func() {
+		l.stopped.Add(1)
+		go l.run()
+	})
+}
+
+func (l *pollLoop) stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+	})
+	l.stopped.Wait()
+}
+
+func (l *pollLoop) run() {
+	defer l.stopped.Done()
+
+	timer := time.NewTimer(l.nextWait())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-timer.C:
+			l.pollSafely()
+			timer.Reset(l.nextWait())
+		}
+	}
+}
+
+// the interval plus a random jitter so pollers started together drift apart
+func (l *pollLoop) nextWait() time.Duration {
+	if l.jitter <= 0 {
+		return l.interval
+	}
+
+	return l.interval + rand.N(l.jitter)
+}
+
+// a panicking poll is logged and the loop keeps running
+func (l *pollLoop) pollSafely() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Poller panic recovered:\n"+
+				"  Poller: %s\n"+
+				"  Panic: %v\n"+
+				"  Stack: %s\n",
+				l.name,
+				r,
+				debug.Stack())
+		}
+	}()
+
+	l.poll()
+}
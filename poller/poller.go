@@ -0,0 +1,95 @@
+package poller
+
+import (
+	"errors"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// returns the measurement and metadata of a poll
+type MeasureFunc func() (measurement map[string]interface{}, metadata map[string]interface{})
+
+// the interval of a poller without WithInterval
+const DefaultInterval = 10 * time.Second
+
+// returned by the constructors when the interval is not positive
+var ErrInvalidInterval = errors.New("poller: the interval must be positive")
+
+// poller option func
+type PollerOption func(poller *Poller)
+
+/*
+A poller that periodically calls a measure func and triggers the event
+with the returned measurement and metadata.
+a measure func that panics is logged and polled again on the next interval.
+like the runtime poller, it is a telemetry.Service the provider can start and stop.
+*/
+type Poller struct {
+	provider telemetry.TelemetryInterface
+	event    string
+	measure  MeasureFunc
+	loop     *pollLoop
+}
+
+/*
+Registers a new poller for the event.
+if no options are provided, the default sets
+interval to 10s,
+jitter to 0
+*/
+func NewPoller(provider telemetry.TelemetryInterface, event string, measure MeasureFunc, options ...PollerOption) (*Poller, error) {
+	poller := &Poller{
+		provider: provider,
+		event:    event,
+		measure:  measure,
+	}
+	poller.loop = newPollLoop(event, DefaultInterval, poller.Poll)
+
+	for _, option := range options {
+		option(poller)
+	}
+
+	if poller.loop.interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	return poller, nil
+}
+
+// sets how often the measure func is called
+func WithInterval(interval time.Duration) PollerOption {
+	return func(poller *Poller) {
+		poller.loop.interval = interval
+	}
+}
+
+// adds a random delay up to jitter to every interval
+func WithJitter(jitter time.Duration) PollerOption {
+	return func(poller *Poller) {
+		poller.loop.jitter = jitter
+	}
+}
+
+// starts polling on the interval, starting twice is a no-op
+func (p *Poller) Start() {
+	p.loop.start()
+}
+
+// stops polling and waits for the running poll to finish
+func (p *Poller) Stop() {
+	p.loop.stop()
+}
+
+// calls the measure func and triggers the event
+func (p *Poller) Poll() {
+	measurement, metadata := p.measure()
+	if measurement == nil {
+		measurement = map[string]interface{}{}
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	p.provider.TriggerEvent(p.event, measurement, metadata)
+}
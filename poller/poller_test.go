@@ -0,0 +1,133 @@
+package poller_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/poller"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestPoller(t *testing.T) {
+	t.Run("should trigger the event with the measurement", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "queue.depth")
+
+		queuePoller, _ := poller.NewPoller(telemetry, "queue.depth", func() (map[string]interface{}, map[string]interface{}) {
+			return map[string]interface{}{"depth": 3}, map[string]interface{}{"queue": "emails"}
+		})
+		queuePoller.Poll()
+
+		if !mailer.AssertReceived("queue.depth", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Measurement["depth"] == 3 && box[0].Metadata["queue"] == "emails"
+		}) {
+			t.Errorf("should assert received the polled measurement")
+		}
+	})
+
+	t.Run("should poll on the interval with jitter", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "cache.size")
+
+		cachePoller, _ := poller.NewPoller(telemetry, "cache.size", func() (map[string]interface{}, map[string]interface{}) {
+			return map[string]interface{}{"size": 10}, nil
+		}, poller.WithInterval(5*time.Millisecond), poller.WithJitter(5*time.Millisecond))
+		cachePoller.Start()
+		defer cachePoller.Stop()
+
+		if !mailer.AssertReceive("cache.size", 1000, func(event string, box ...mailbox.MailData) bool {
+			return len(box) >= 3
+		}) {
+			t.Errorf("should poll on the interval")
+		}
+	})
+
+	t.Run("should keep polling after the measure func panics", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "db.pool")
+
+		var calls atomic.Int64
+		poolPoller, _ := poller.NewPoller(telemetry, "db.pool", func() (map[string]interface{}, map[string]interface{}) {
+			if calls.Add(1) == 1 {
+				panic("pool is closed")
+			}
+			return map[string]interface{}{"open": 4}, nil
+		}, poller.WithInterval(5*time.Millisecond))
+		poolPoller.Start()
+		defer poolPoller.Stop()
+
+		if !mailer.AssertReceive("db.pool", 1000, func(event string, box ...mailbox.MailData) bool {
+			return box[0].Measurement["open"] == 4
+		}) {
+			t.Errorf("should poll again after the panic")
+		}
+	})
+
+	t.Run("should stop polling", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "queue.depth")
+
+		queuePoller, _ := poller.NewPoller(telemetry, "queue.depth", func() (map[string]interface{}, map[string]interface{}) {
+			return map[string]interface{}{"depth": 0}, nil
+		}, poller.WithInterval(5*time.Millisecond))
+		queuePoller.Start()
+
+		mailer.AssertReceive("queue.depth", 1000, func(event string, box ...mailbox.MailData) bool {
+			return true
+		})
+
+		queuePoller.Stop()
+		queuePoller.Stop() // stopping twice is safe
+		polled := mailer.Stats("queue.depth").Total
+
+		time.Sleep(30 * time.Millisecond)
+		if total := mailer.Stats("queue.depth").Total; total != polled {
+			t.Errorf("expected no polls after stopping, got %d more", total-polled)
+		}
+	})
+
+	t.Run("should poll once per interval when started twice", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "queue.depth")
+
+		queuePoller, _ := poller.NewPoller(telemetry, "queue.depth", func() (map[string]interface{}, map[string]interface{}) {
+			return map[string]interface{}{"depth": 0}, nil
+		}, poller.WithInterval(100*time.Millisecond))
+		queuePoller.Start()
+		queuePoller.Start()
+
+		time.Sleep(150 * time.Millisecond)
+		queuePoller.Stop()
+
+		if total := mailer.Stats("queue.depth").Total; total != 1 {
+			t.Errorf("expected a single poll, got %d", total)
+		}
+	})
+
+	t.Run("should reject intervals that are not positive", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		for _, interval := range []time.Duration{0, -time.Second} {
+			_, err := poller.NewPoller(telemetry, "queue.depth", func() (map[string]interface{}, map[string]interface{}) {
+				return nil, nil
+			}, poller.WithInterval(interval))
+			if !errors.Is(err, poller.ErrInvalidInterval) {
+				t.Errorf("expected an invalid interval error for %v, got %v", interval, err)
+			}
+
+			if _, err := poller.NewRuntimePoller(telemetry, interval); !errors.Is(err, poller.ErrInvalidInterval) {
+				t.Errorf("expected an invalid interval error for %v, got %v", interval, err)
+			}
+		}
+	})
+}
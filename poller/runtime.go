@@ -0,0 +1,120 @@
+package poller
+
+import (
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// events triggered by the runtime poller
+const (
+	MemoryEvent     = "vm.memory"
+	GoroutinesEvent = "vm.goroutines"
+	GCEvent         = "vm.gc"
+)
+
+// runtime/metrics samples read on every poll
+const (
+	goroutinesMetric = "/sched/goroutines:goroutines"
+	heapGoalMetric   = "/gc/heap/goal:bytes"
+)
+
+/*
+A poller that periodically reads the go runtime and triggers
+vm.memory, vm.goroutines and vm.gc events with the values as measurements.
+
+vm.memory has total, heap_alloc, heap_sys, heap_inuse, heap_objects and stack_inuse in bytes.
+vm.goroutines has count.
+vm.gc has count, pause_total and last_pause as time.Duration, cpu_fraction and heap_goal in bytes.
+
+the poller is a telemetry.Service, pass it to the StartServices of the
+provider to start it and stop it when the provider is stopped.
+*/
+type RuntimePoller struct {
+	provider telemetry.TelemetryInterface
+	samples  []metrics.Sample
+	mu       sync.Mutex // guards the samples, as Poll may be called while polling
+	loop     *pollLoop
+}
+
+func NewRuntimePoller(provider telemetry.TelemetryInterface, interval time.Duration) (*RuntimePoller, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	runtimePoller := &RuntimePoller{
+		provider: provider,
+		samples: []metrics.Sample{
+			{Name: goroutinesMetric},
+			{Name: heapGoalMetric},
+		},
+	}
+	runtimePoller.loop = newPollLoop("vm", interval, runtimePoller.Poll)
+
+	return runtimePoller, nil
+}
+
+// starts polling on the interval, starting twice is a no-op
+func (p *RuntimePoller) Start() {
+	p.loop.start()
+}
+
+// stops polling and waits for the running poll to finish
+func (p *RuntimePoller) Stop() {
+	p.loop.stop()
+}
+
+// reads the runtime and triggers the events
+func (p *RuntimePoller) Poll() {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	p.mu.Lock()
+	metrics.Read(p.samples)
+	goroutines, heapGoal := sampleValue(p.samples[0]), sampleValue(p.samples[1])
+	p.mu.Unlock()
+
+	p.provider.TriggerEvent(MemoryEvent, map[string]interface{}{
+		"total":        memStats.Sys,
+		"heap_alloc":   memStats.HeapAlloc,
+		"heap_sys":     memStats.HeapSys,
+		"heap_inuse":   memStats.HeapInuse,
+		"heap_objects": memStats.HeapObjects,
+		"stack_inuse":  memStats.StackInuse,
+	}, map[string]interface{}{})
+
+	p.provider.TriggerEvent(GoroutinesEvent, map[string]interface{}{
+		"count": goroutines,
+	}, map[string]interface{}{})
+
+	// the last pause is held in a circular buffer indexed by the gc count
+	lastPause := uint64(0)
+	if memStats.NumGC > 0 {
+		lastPause = memStats.PauseNs[(memStats.NumGC+255)%256]
+	}
+
+	p.provider.TriggerEvent(GCEvent, map[string]interface{}{
+		"count":        memStats.NumGC,
+		"pause_total":  time.Duration(memStats.PauseTotalNs),
+		"last_pause":   time.Duration(lastPause),
+		"cpu_fraction": memStats.GCCPUFraction,
+		"heap_goal":    heapGoal,
+	}, map[string]interface{}{})
+}
+
+// private methods
+
+// returns the value of the sample, zero if the runtime does not support it
+func sampleValue(sample metrics.Sample) uint64 {
+	switch sample.Value.Kind() {
+	case metrics.KindUint64:
+		return sample.Value.Uint64()
+	case metrics.KindFloat64:
+		return uint64(sample.Value.Float64())
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,99 @@
+package poller_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/poller"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestRuntimePollerPoll(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, telemetry, poller.MemoryEvent, poller.GoroutinesEvent, poller.GCEvent)
+
+	runtime.GC()
+	runtimePoller, _ := poller.NewRuntimePoller(telemetry, time.Hour)
+	runtimePoller.Poll()
+
+	if !mailer.AssertReceived(poller.MemoryEvent, func(event string, box ...mailbox.MailData) bool {
+		total, _ := box[0].Measurement["total"].(uint64)
+		heapAlloc, _ := box[0].Measurement["heap_alloc"].(uint64)
+		return total > 0 && heapAlloc > 0
+	}) {
+		t.Errorf("should assert received the memory event")
+	}
+
+	if !mailer.AssertReceived(poller.GoroutinesEvent, func(event string, box ...mailbox.MailData) bool {
+		count, _ := box[0].Measurement["count"].(uint64)
+		return count > 0
+	}) {
+		t.Errorf("should assert received the goroutines event")
+	}
+
+	if !mailer.AssertReceived(poller.GCEvent, func(event string, box ...mailbox.MailData) bool {
+		count, _ := box[0].Measurement["count"].(uint32)
+		_, ok := box[0].Measurement["last_pause"].(time.Duration)
+		return count > 0 && ok
+	}) {
+		t.Errorf("should assert received the gc event")
+	}
+}
+
+func TestRuntimePollerInterval(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, telemetry, poller.GoroutinesEvent)
+
+	runtimePoller, _ := poller.NewRuntimePoller(telemetry, 10*time.Millisecond)
+	runtimePoller.Start()
+
+	if !mailer.AssertReceive(poller.GoroutinesEvent, 1000, func(event string, box ...mailbox.MailData) bool {
+		return len(box) >= 3
+	}) {
+		t.Errorf("should poll on the interval")
+	}
+
+	runtimePoller.Stop()
+	polled := mailer.Stats(poller.GoroutinesEvent).Total
+
+	// no polls after stopping
+	time.Sleep(50 * time.Millisecond)
+	if total := mailer.Stats(poller.GoroutinesEvent).Total; total != polled {
+		t.Errorf("expected no polls after stopping, got %d more", total-polled)
+	}
+}
+
+func TestRuntimePollerLifecycle(t *testing.T) {
+	// register telemetry
+	provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, provider, poller.GoroutinesEvent)
+
+	runtimePoller, _ := poller.NewRuntimePoller(provider, 5*time.Millisecond)
+	provider.(telemetry.Lifecycle).StartServices(runtimePoller)
+
+	// polling by hand while the loop polls
+	for i := 0; i < 10; i++ {
+		runtimePoller.Poll()
+	}
+
+	if !mailer.AssertReceive(poller.GoroutinesEvent, 1000, func(event string, box ...mailbox.MailData) bool {
+		return len(box) >= 12
+	}) {
+		t.Errorf("should poll once started by the provider")
+	}
+
+	if err := provider.(telemetry.Lifecycle).Stop(t.Context()); err != nil {
+		t.Fatalf("stop should succeed: %v", err)
+	}
+	polled := mailer.Stats(poller.GoroutinesEvent).Total
+
+	time.Sleep(30 * time.Millisecond)
+	if total := mailer.Stats(poller.GoroutinesEvent).Total; total != polled {
+		t.Errorf("expected no polls once the provider stopped, got %d more", total-polled)
+	}
+}
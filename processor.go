@@ -0,0 +1,10 @@
+package telemetry
+
+/*
+A processor runs on every event before it is dispatched to the handlers.
+it may add to or change the measurement and metadata, which are copies of the
+maps passed to TriggerEvent, so the caller never sees the changes.
+processors run once per event, in the order they were added, and only when
+a handler is attached to the event.
+*/
+type ProcessorFunc func(event string, measurement map[string]interface{}, metadata map[string]interface{})
@@ -0,0 +1,216 @@
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+)
+
+// content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+/*
+An exporter that aggregates events into prometheus metrics and serves
+them in the text exposition format.
+counters and sums are exposed as counters, last values as gauges,
+summaries as summaries without quantiles and distributions as histograms.
+the tags of a metric are the only metadata keys exposed as labels.
+*/
+type Exporter struct {
+	handler *metrics.Handler
+}
+
+// exporter will implement the telemetry handler interface
+// and the http handler interface
+
+func NewExporter(id string, definitions ...metrics.Metric) *Exporter {
+	return &Exporter{
+		handler: metrics.NewHandler(id, definitions...),
+	}
+}
+
+func (e *Exporter) ID() string {
+	return e.handler.ID()
+}
+
+func (e *Exporter) Config() interface{} {
+	return e.handler.Config()
+}
+
+func (e *Exporter) AttachedHandlers() []telemetry.EventRegistrar {
+	return e.handler.AttachedHandlers()
+}
+
+// serves the metrics in the text exposition format
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	e.Write(w)
+}
+
+// writes the metrics in the text exposition format
+func (e *Exporter) Write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	// group the series of each metric under a single HELP and TYPE
+	groups := make(map[string][]metrics.Series)
+	names := make([]string, 0)
+	for _, series := range e.handler.Snapshot() {
+		name := metricName(series.Metric)
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], series)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		group := groups[name]
+		metric := group[0].Metric
+
+		if metric.Description != "" {
+			fmt.Fprintf(writer, "# HELP %s %s\n", name, escapeHelp(metric.Description))
+		}
+		fmt.Fprintf(writer, "# TYPE %s %s\n", name, metricType(metric.Kind))
+
+		for _, series := range group {
+			writeSeries(writer, name, series)
+		}
+	}
+
+	return writer.Flush()
+}
+
+// private methods
+
+func writeSeries(w io.Writer, name string, series metrics.Series) {
+	labels := seriesLabels(series)
+
+	switch series.Metric.Kind {
+	case metrics.KindCounter, metrics.KindSum:
+		writeSample(w, name, labels, series.Sum)
+	case metrics.KindLastValue:
+		writeSample(w, name, labels, series.Last)
+	case metrics.KindSummary:
+		writeSample(w, name+"_sum", labels, series.Sum)
+		writeSample(w, name+"_count", labels, float64(series.Count))
+	case metrics.KindDistribution:
+		// buckets are cumulative in the exposition format
+		var cumulative uint64
+		for i, bound := range series.Metric.Buckets {
+			cumulative += series.Buckets[i]
+			writeSample(w, name+"_bucket", append(labels, label{"le", formatFloat(bound)}), float64(cumulative))
+		}
+		writeSample(w, name+"_bucket", append(labels, label{"le", "+Inf"}), float64(series.Count))
+		writeSample(w, name+"_sum", labels, series.Sum)
+		writeSample(w, name+"_count", labels, float64(series.Count))
+	}
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+// returns the labels of the series in the order of the metric tags
+func seriesLabels(series metrics.Series) []label {
+	labels := make([]label, 0, len(series.Metric.Tags)+1)
+	for _, tag := range series.Metric.Tags {
+		// label names can not contain colons unlike metric names
+		labels = append(labels, label{strings.ReplaceAll(sanitizeName(tag), ":", "_"), series.Tags[tag]})
+	}
+
+	return labels
+}
+
+func writeSample(w io.Writer, name string, labels []label, value float64) {
+	if len(labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+		return
+	}
+
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		pairs[i] = label.name + `="` + escapeLabelValue(label.value) + `"`
+	}
+
+	fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+// returns the prometheus type of the metric kind
+func metricType(kind metrics.Kind) string {
+	switch kind {
+	case metrics.KindCounter, metrics.KindSum:
+		return "counter"
+	case metrics.KindLastValue:
+		return "gauge"
+	case metrics.KindSummary:
+		return "summary"
+	case metrics.KindDistribution:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// returns the exposed name of the metric, counters are suffixed with _total
+func metricName(metric metrics.Metric) string {
+	name := sanitizeName(metric.Name)
+
+	if metricType(metric.Kind) == "counter" && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+
+	return name
+}
+
+// replaces the characters prometheus does not allow in names, such as the dots in event names
+func sanitizeName(name string) string {
+	var sanitized strings.Builder
+	for i, r := range name {
+		valid := r == '_' || r == ':' ||
+			(r >= 'a' && r <= 'z') ||
+			(r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9' && i > 0)
+
+		if valid {
+			sanitized.WriteRune(r)
+		} else {
+			sanitized.WriteByte('_')
+		}
+	}
+
+	return sanitized.String()
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
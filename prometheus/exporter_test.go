@@ -0,0 +1,113 @@
+package prometheus_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+	"github.com/trexreigns/gopulse/prometheus"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestExporter(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	exporter := prometheus.NewExporter("prometheus",
+		metrics.Counter("http.server.request.end", "", metrics.WithName("http.requests"), metrics.WithTags("method", "status"), metrics.WithDescription("Handled requests")),
+		metrics.LastValue("vm.goroutines", "count"),
+		metrics.Summary("db.query.end", "rows"),
+		metrics.Distribution("http.server.request.end", "duration", metrics.WithName("http.request.duration"), metrics.WithBuckets(10, 100)),
+	)
+	telemetry.AddHandlers(exporter)
+
+	requests := []struct {
+		method   string
+		status   int
+		duration int64
+	}{
+		{"GET", 200, 5},
+		{"GET", 200, 50},
+		{"POST", 500, 500},
+	}
+	for _, request := range requests {
+		telemetry.TriggerEvent("http.server.request.end", map[string]interface{}{
+			"duration": request.duration,
+		}, map[string]interface{}{
+			"method": request.method,
+			"status": request.status,
+			"secret": "not exposed",
+		})
+	}
+	telemetry.TriggerEvent("vm.goroutines", map[string]interface{}{"count": 12}, map[string]interface{}{})
+	telemetry.TriggerEvent("db.query.end", map[string]interface{}{"rows": 3}, map[string]interface{}{})
+
+	server := httptest.NewServer(exporter)
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to scrape the exporter: %v", err)
+	}
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != prometheus.ContentType {
+		t.Errorf("unexpected content type %s", contentType)
+	}
+
+	body, _ := io.ReadAll(response.Body)
+	exposition := string(body)
+
+	expected := []string{
+		"# HELP http_requests_total Handled requests",
+		"# TYPE http_requests_total counter",
+		`http_requests_total{method="GET",status="200"} 2`,
+		`http_requests_total{method="POST",status="500"} 1`,
+		"# TYPE vm_goroutines_count gauge",
+		"vm_goroutines_count 12",
+		"# TYPE db_query_end_rows summary",
+		"db_query_end_rows_sum 3",
+		"db_query_end_rows_count 1",
+		"# TYPE http_request_duration histogram",
+		`http_request_duration_bucket{le="10"} 1`,
+		`http_request_duration_bucket{le="100"} 2`,
+		`http_request_duration_bucket{le="+Inf"} 3`,
+		"http_request_duration_sum 555",
+		"http_request_duration_count 3",
+	}
+	for _, line := range expected {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("expected %q in the exposition:\n%s", line, exposition)
+		}
+	}
+
+	if strings.Contains(exposition, "secret") {
+		t.Errorf("metadata outside the tags should not be exposed:\n%s", exposition)
+	}
+}
+
+func TestExporterEscapesLabelValues(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	exporter := prometheus.NewExporter("prometheus", metrics.Counter("cache.miss", "", metrics.WithTags("cache.key")))
+	telemetry.AddHandlers(exporter)
+
+	telemetry.TriggerEvent("cache.miss", map[string]interface{}{}, map[string]interface{}{
+		"cache.key": "user \"42\"\nline\\",
+	})
+
+	var exposition strings.Builder
+	if err := exporter.Write(&exposition); err != nil {
+		t.Fatalf("failed to write the exposition: %v", err)
+	}
+
+	expected := `cache_miss_total{cache_key="user \"42\"\nline\\"} 1` + "\n"
+	if !strings.Contains(exposition.String(), expected) {
+		t.Errorf("expected %q in the exposition:\n%s", expected, exposition.String())
+	}
+}
@@ -0,0 +1,100 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+/*
+A view of a provider that prefixes event names and adds default metadata.
+events are triggered as {prefix}.{event}, and the default metadata is merged
+into the metadata of every event, the metadata passed by the caller wins.
+the view uses the handlers and pool of its provider, so handlers added to
+the view are added to the provider and are attached to full event names.
+*/
+type ScopedTelemetry struct {
+	provider *TelemetryProvider
+	prefix   string
+	metadata map[string]interface{}
+}
+
+// returns a view of the provider, see ScopedTelemetry
+func (t *TelemetryProvider) With(prefix string, metadata map[string]interface{}) telemetry.TelemetryInterface {
+	return &ScopedTelemetry{
+		provider: t,
+		prefix:   prefix,
+		metadata: mergeMetadata(nil, metadata),
+	}
+}
+
+// returns a view nested in this view, the prefixes are joined and the metadata merged
+func (s *ScopedTelemetry) With(prefix string, metadata map[string]interface{}) telemetry.TelemetryInterface {
+	return &ScopedTelemetry{
+		provider: s.provider,
+		prefix:   s.event(prefix),
+		metadata: mergeMetadata(s.metadata, metadata),
+	}
+}
+
+func (s *ScopedTelemetry) AddHandlers(handlers ...telemetry.TelemetryHandlerInterface) error {
+	return s.provider.AddHandlers(handlers...)
+}
+
+func (s *ScopedTelemetry) RemoveHandlers(handlers ...telemetry.TelemetryHandlerInterface) error {
+	return s.provider.RemoveHandlers(handlers...)
+}
+
+func (s *ScopedTelemetry) TriggerEvent(event string, measurement map[string]interface{}, metadata map[string]interface{}) error {
+	return s.provider.TriggerEvent(s.event(event), measurement, mergeMetadata(s.metadata, metadata))
+}
+
+// the default metadata is merged into the metadata of the start, end and panic events
+func (s *ScopedTelemetry) TriggerSpan(event string, metadata map[string]interface{}, spanFunc telemetry.SpanFunc[any]) (any, error) {
+	return s.provider.triggerSpan(s.event(event), mergeMetadata(s.metadata, metadata), s.metadata, func() (any, error, map[string]interface{}, map[string]interface{}) {
+		result, err, spanMeasurement, spanMetadata := spanFunc()
+		return result, err, spanMeasurement, mergeMetadata(s.metadata, spanMetadata)
+	})
+}
+
+func (s *ScopedTelemetry) WaitIdle(ctx context.Context, quiet time.Duration) error {
+	return s.provider.WaitIdle(ctx, quiet)
+}
+
+// the services are started and stopped with the provider of the view
+func (s *ScopedTelemetry) StartServices(services ...telemetry.Service) {
+	s.provider.StartServices(services...)
+}
+
+// stops the provider of the view
+func (s *ScopedTelemetry) Stop(ctx context.Context) error {
+	return s.provider.Stop(ctx)
+}
+
+// private methods
+
+// prefixes the event name
+func (s *ScopedTelemetry) event(event string) string {
+	if s.prefix == "" {
+		return event
+	}
+	if event == "" {
+		return s.prefix
+	}
+
+	return s.prefix + "." + event
+}
+
+// returns a new map with the defaults overridden by the metadata
+func mergeMetadata(defaults map[string]interface{}, metadata map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(metadata))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range metadata {
+		merged[key] = value
+	}
+
+	return merged
+}
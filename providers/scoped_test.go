@@ -0,0 +1,122 @@
+package providers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func TestTelemetryWith(t *testing.T) {
+	t.Run("should prefix events and merge the default metadata", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "billing.invoice.sent")
+
+		billing := provider.(telemetry.Scoper).With("billing", map[string]interface{}{"service": "billing", "component": "invoices"})
+		billing.TriggerEvent("invoice.sent", map[string]interface{}{"count": 1}, map[string]interface{}{"component": "mailer"})
+
+		if !mailer.AssertReceived("billing.invoice.sent", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["service"] == "billing" &&
+				box[0].Metadata["component"] == "mailer" &&
+				box[0].Measurement["count"] == 1
+		}) {
+			t.Errorf("should assert received the prefixed event")
+		}
+	})
+
+	t.Run("should prefix spans and merge the default metadata", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "billing.charge.start", "billing.charge.end")
+
+		billing := provider.(telemetry.Scoper).With("billing", map[string]interface{}{"service": "billing"})
+		result, _ := billing.TriggerSpan("charge", nil, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			return "charged", nil, nil, map[string]interface{}{"status": "ok"}
+		})
+
+		if result != "charged" {
+			t.Errorf("expected the span result, got %v", result)
+		}
+
+		if !mailer.AssertReceived("billing.charge.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["service"] == "billing" && box[0].Metadata["status"] == "ok"
+		}) {
+			t.Errorf("should assert received the prefixed end event")
+		}
+
+		if !mailer.AssertSpanCompleted("billing.charge", 0) {
+			t.Errorf("should complete the prefixed span")
+		}
+	})
+
+	t.Run("should nest views", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, provider, "billing.stripe.request")
+
+		billing := provider.(telemetry.Scoper).With("billing", map[string]interface{}{"service": "billing"})
+		stripe := billing.(telemetry.Scoper).With("stripe", map[string]interface{}{"component": "stripe"})
+		stripe.TriggerEvent("request", map[string]interface{}{}, nil)
+
+		if !mailer.AssertReceived("billing.stripe.request", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["service"] == "billing" && box[0].Metadata["component"] == "stripe"
+		}) {
+			t.Errorf("should assert received the nested event")
+		}
+	})
+
+	t.Run("should share the handlers and pool of the provider", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+		billing := provider.(telemetry.Scoper).With("billing", nil)
+
+		// handlers added to the view receive events of the provider
+		mailer := mailbox.ForTest(t, billing, "gopulse.event.shared", "billing.event.shared")
+		provider.TriggerEvent("gopulse.event.shared", map[string]interface{}{}, map[string]interface{}{})
+		billing.TriggerEvent("event.shared", map[string]interface{}{}, map[string]interface{}{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := billing.(telemetry.Idler).WaitIdle(ctx, 10*time.Millisecond); err != nil {
+			t.Fatalf("should become idle, got %v", err)
+		}
+
+		if mailer.Stats("gopulse.event.shared").Total != 1 || mailer.Stats("billing.event.shared").Total != 1 {
+			t.Errorf("should receive the events of the provider and the view")
+		}
+	})
+}
+
+func TestTelemetryWithPanic(t *testing.T) {
+	provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, provider, "billing.charge.start", "billing.charge.panic")
+
+	billing := provider.(telemetry.Scoper).With("billing", map[string]interface{}{"service": "billing", "component": "charges"})
+
+	func() {
+		defer func() {
+			if r := recover(); r != "card declined" {
+				t.Errorf("expected the panic to be repanicked, got %v", r)
+			}
+		}()
+
+		billing.TriggerSpan("charge", nil, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			panic("card declined")
+		})
+	}()
+
+	if !mailer.AssertReceived("billing.charge.panic", func(event string, box ...mailbox.MailData) bool {
+		metadata := box[0].Metadata
+		return len(box) == 1 &&
+			metadata["service"] == "billing" &&
+			metadata["component"] == "charges" &&
+			metadata["error"] == "card declined"
+	}) {
+		t.Errorf("should add the default metadata to the panic event")
+	}
+
+	if !mailer.AssertSpanPanicked("billing.charge", 0) {
+		t.Errorf("should assert the span panicked")
+	}
+}
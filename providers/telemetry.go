@@ -1,9 +1,11 @@
 package providers
 
 import (
+	"context"
 	"log"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	telemetry "github.com/trexreigns/gopulse"
@@ -24,8 +26,18 @@ type TelemetryProvider struct {
 	config   *telemetry.TelemetryConfig
 	pool     pool.PoolInterface
 	mu       sync.RWMutex
+
+	inflight     atomic.Int64 // handlers queued or running
+	lastActivity atomic.Int64 // unix nano of the last triggered event
+
+	services []telemetry.Service // started with StartServices, stopped with Stop
+	stopped  bool
+	stopOnce sync.Once
 }
 
+// how often WaitIdle checks whether the provider has settled
+const idlePollInterval = time.Millisecond
+
 func NewTelemetry(config *telemetry.TelemetryConfig) telemetry.TelemetryInterface {
 	telemetryProvider := &TelemetryProvider{
 		handlers: make(map[string]telemetry.TelemetryHandlerInterface),
@@ -66,6 +78,8 @@ func (t *TelemetryProvider) RemoveHandlers(handlers ...telemetry.TelemetryHandle
 }
 
 func (t *TelemetryProvider) TriggerEvent(event string, measurement map[string]interface{}, metadata map[string]interface{}) error {
+	t.lastActivity.Store(time.Now().UnixNano())
+
 	t.mu.RLock()
 
 	// get the event funcs
@@ -77,6 +91,14 @@ func (t *TelemetryProvider) TriggerEvent(event string, measurement map[string]in
 		return nil
 	}
 
+	// run the processors on copies so the caller's maps are left unchanged
+	if len(t.config.Processors) > 0 {
+		measurement, metadata = copyMap(measurement), copyMap(metadata)
+		for _, processor := range t.config.Processors {
+			t.executeProcessorSafely(processor, event, measurement, metadata)
+		}
+	}
+
 	// execute the event funcs
 	t.executeEventFuncs(eventFuncs, event, measurement, metadata)
 
@@ -84,18 +106,26 @@ func (t *TelemetryProvider) TriggerEvent(event string, measurement map[string]in
 }
 
 func (t *TelemetryProvider) TriggerSpan(event string, metadata map[string]interface{}, spanFunc telemetry.SpanFunc[any]) (any, error) {
+	return t.triggerSpan(event, metadata, nil, spanFunc)
+}
+
+// triggers the span, the panic defaults are merged into the metadata of the panic event
+func (t *TelemetryProvider) triggerSpan(event string, metadata map[string]interface{}, panicDefaults map[string]interface{}, spanFunc telemetry.SpanFunc[any]) (any, error) {
+	// identify the span so its events can be paired by handlers
+	ids, metadata := spanIdentity(metadata)
+
 	// lets defer any failures
 	// pass recovery code here
 	defer func() {
 		if r := recover(); r != nil {
 			// log the error
 			errorTime := time.Now().UnixMilli()
-			metadata := map[string]interface{}{
+			metadata := mergeMetadata(panicDefaults, map[string]interface{}{
 				"error":      r,
 				"errorTime":  errorTime,
 				"stackTrace": string(debug.Stack()),
-			}
-			t.TriggerEvent(event+".panic", map[string]interface{}{}, metadata)
+			})
+			t.TriggerEvent(event+telemetry.SpanPanicSuffix, ids.measurement(map[string]interface{}{}), metadata)
 
 			// repopagate panic
 			panic(r)
@@ -106,10 +136,10 @@ func (t *TelemetryProvider) TriggerSpan(event string, metadata map[string]interf
 	startTime := time.Now().UnixMilli()
 
 	// lets trigger the event
-	measurement := map[string]interface{}{
+	measurement := ids.measurement(map[string]interface{}{
 		"start_time": startTime, // start time
-	}
-	startEvent := event + ".start"
+	})
+	startEvent := event + telemetry.SpanStartSuffix
 	t.TriggerEvent(startEvent, measurement, metadata) // trigger the event
 
 	// execute the span func
@@ -118,21 +148,144 @@ func (t *TelemetryProvider) TriggerSpan(event string, metadata map[string]interf
 	// get the end time
 	endTime := time.Now().UnixMilli()
 
+	// copy the measurement as the span func may still hold it
+	spanMeasurement = copyMap(spanMeasurement)
+
 	// get the duration
 	duration := endTime - startTime
 	spanMeasurement["duration"] = duration
 	spanMeasurement["end_time"] = endTime
+	ids.measurement(spanMeasurement)
 
 	// lets trigger the event
-	endEvent := event + ".end"
+	endEvent := event + telemetry.SpanEndSuffix
 	t.TriggerEvent(endEvent, spanMeasurement, spanMetadata) // trigger the event
 
 	// return the result
 	return result, err
 }
 
+/*
+Blocks until the provider is idle.
+the provider is idle when no handler is queued or running and no event
+has been triggered for the quiet window. returns the context error if
+the context is done first.
+*/
+func (t *TelemetryProvider) WaitIdle(ctx context.Context, quiet time.Duration) error {
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+
+	for {
+		if t.inflight.Load() == 0 && time.Since(time.Unix(0, t.lastActivity.Load())) >= quiet {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+/*
+Starts the services and stops them when the provider is stopped.
+services added after the provider stopped are not started.
+*/
+func (t *TelemetryProvider) StartServices(services ...telemetry.Service) {
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	t.services = append(t.services, services...)
+	t.mu.Unlock()
+
+	// started without the lock as a service may trigger events when it starts
+	for _, service := range services {
+		service.Start()
+	}
+}
+
+/*
+Stops the services in reverse order, waits until the provider is idle
+and stops the worker pool. returns the context error if the context is
+done first, the pool is stopped either way. stopping twice is a no-op.
+*/
+func (t *TelemetryProvider) Stop(ctx context.Context) error {
+	var err error
+	t.stopOnce.Do(func() {
+		t.mu.Lock()
+		t.stopped = true
+		services := t.services
+		t.services = nil
+		t.mu.Unlock()
+
+		for i := len(services) - 1; i >= 0; i-- {
+			services[i].Stop()
+		}
+
+		err = t.WaitIdle(ctx, 0)
+		if t.pool != nil {
+			t.pool.Stop()
+		}
+	})
+
+	return err
+}
+
 // private methods
 
+// the ids added to the measurements of a span
+type spanIDs struct {
+	spanID   string
+	traceID  string
+	parentID string
+}
+
+/*
+uses the ids seeded in the metadata, generating the missing span and trace ids.
+the seeded ids are moved to the measurements, so the metadata is returned without them.
+nested spans are not linked, a span without seeded ids always starts a new trace.
+*/
+func spanIdentity(metadata map[string]interface{}) (spanIDs, map[string]interface{}) {
+	ids := spanIDs{}
+	ids.spanID, _ = metadata[telemetry.SpanIDKey].(string)
+	ids.traceID, _ = metadata[telemetry.TraceIDKey].(string)
+	ids.parentID, _ = metadata[telemetry.ParentSpanIDKey].(string)
+
+	if ids.spanID != "" || ids.traceID != "" || ids.parentID != "" {
+		// copy the metadata as the caller owns it
+		seeded := metadata
+		metadata = make(map[string]interface{}, len(seeded))
+		for key, value := range seeded {
+			if key != telemetry.SpanIDKey && key != telemetry.TraceIDKey && key != telemetry.ParentSpanIDKey {
+				metadata[key] = value
+			}
+		}
+	}
+
+	if ids.spanID == "" {
+		ids.spanID = telemetry.NewSpanID()
+	}
+	if ids.traceID == "" {
+		ids.traceID = telemetry.NewTraceID()
+	}
+
+	return ids, metadata
+}
+
+// adds the ids to the measurement
+func (s spanIDs) measurement(measurement map[string]interface{}) map[string]interface{} {
+	measurement[telemetry.SpanIDKey] = s.spanID
+	measurement[telemetry.TraceIDKey] = s.traceID
+	if s.parentID != "" {
+		measurement[telemetry.ParentSpanIDKey] = s.parentID
+	}
+
+	return measurement
+}
+
 // get an event func for a specific handler
 func (t *TelemetryProvider) getEventFunc(event string) []executableEvent {
 	// get the event funcs
@@ -154,16 +307,31 @@ func (t *TelemetryProvider) getEventFunc(event string) []executableEvent {
 	return eventFuncs
 }
 
-// execute the event funcs
+/*
+execute the event funcs.
+every handler gets its own copy of the measurement and metadata, so a handler
+writing to them never races with other handlers or the caller. the copies
+are shallow, nested maps and slices are still shared.
+*/
 func (t *TelemetryProvider) executeEventFuncs(eventFuncs []executableEvent, event string, measurement map[string]interface{}, metadata map[string]interface{}) error {
 	// execute the event funcs
 	for _, eventFunc := range eventFuncs {
+		t.inflight.Add(1)
+
+		measurement, metadata := copyMap(measurement), copyMap(metadata)
 		if t.config.AllowConcurrentExecution {
-			t.pool.Submit(func() {
+			submitted := t.pool.Submit(func() {
+				defer t.inflight.Add(-1)
 				t.executeHandlerSafely(eventFunc, event, measurement, metadata)
 			})
+
+			// the job was dropped so it will never run
+			if !submitted {
+				t.inflight.Add(-1)
+			}
 		} else {
 			t.executeHandlerSafely(eventFunc, event, measurement, metadata)
+			t.inflight.Add(-1)
 		}
 	}
 
@@ -189,3 +357,30 @@ func (t *TelemetryProvider) executeHandlerSafely(eventFunc executableEvent, even
 
 	eventFunc.handler(event, measurement, metadata, eventFunc.config)
 }
+
+// a panicking processor is logged and the event is still dispatched
+func (t *TelemetryProvider) executeProcessorSafely(processor telemetry.ProcessorFunc, event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Processor panic recovered:\n"+
+				"  Event: %s\n"+
+				"  Panic: %v\n"+
+				"  Stack: %s\n",
+				event,
+				r,
+				debug.Stack())
+		}
+	}()
+
+	processor(event, measurement, metadata)
+}
+
+// returns a shallow copy of the map, an empty map if nil
+func copyMap(source map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(source))
+	for key, value := range source {
+		copied[key] = value
+	}
+
+	return copied
+}
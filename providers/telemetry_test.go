@@ -0,0 +1,347 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+)
+
+func newAsyncTelemetry() telemetry.TelemetryInterface {
+	return providers.NewTelemetry(telemetry.NewTelemetryConfig(
+		telemetry.WithAllowConcurrentExecution(true),
+		telemetry.WithConcurrentPoolSize(4),
+		telemetry.WithConcurrentBufferSize(100),
+	))
+}
+
+func TestTelemetryWaitIdle(t *testing.T) {
+	t.Run("should wait for queued handlers to run", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+		mailer := mailbox.ForTest(t, provider, "gopulse.event.idle")
+
+		for i := 0; i < 50; i++ {
+			provider.TriggerEvent("gopulse.event.idle", map[string]interface{}{"count": i}, map[string]interface{}{})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := provider.(telemetry.Idler).WaitIdle(ctx, 10*time.Millisecond); err != nil {
+			t.Fatalf("should become idle, got %v", err)
+		}
+
+		// every handler has run so the check is deterministic
+		if !mailer.AssertReceived("gopulse.event.idle", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 50
+		}) {
+			t.Errorf("should assert received every event after waiting for idle")
+		}
+	})
+
+	t.Run("should wait for slow handlers", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+		mailer := mailbox.ForTest(t, provider, "gopulse.event.idle.slow")
+		provider.AddHandlers(&slowHandler{event: "gopulse.event.idle.slow", delay: 50 * time.Millisecond})
+
+		provider.TriggerEvent("gopulse.event.idle.slow", map[string]interface{}{}, map[string]interface{}{})
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := provider.(telemetry.Idler).WaitIdle(ctx, 0); err != nil {
+			t.Fatalf("should become idle, got %v", err)
+		}
+
+		if time.Since(start) < 40*time.Millisecond {
+			t.Errorf("should have waited for the slow handler to finish")
+		}
+
+		if !mailer.AssertReceived("gopulse.event.idle.slow", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 1
+		}) {
+			t.Errorf("should assert received after waiting for idle")
+		}
+	})
+
+	t.Run("should wait for the quiet window", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		provider.TriggerEvent("gopulse.event.idle.quiet", map[string]interface{}{}, map[string]interface{}{})
+
+		start := time.Now()
+		if err := provider.(telemetry.Idler).WaitIdle(context.Background(), 30*time.Millisecond); err != nil {
+			t.Fatalf("should become idle, got %v", err)
+		}
+
+		if time.Since(start) < 20*time.Millisecond {
+			t.Errorf("should have waited for the quiet window")
+		}
+	})
+
+	t.Run("should return the context error when events keep arriving", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		provider.TriggerEvent("gopulse.event.idle.busy", map[string]interface{}{}, map[string]interface{}{})
+
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					provider.TriggerEvent("gopulse.event.idle.busy", map[string]interface{}{}, map[string]interface{}{})
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		if err := provider.(telemetry.Idler).WaitIdle(ctx, 20*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
+// handler that blocks for a while before returning
+type slowHandler struct {
+	event string
+	delay time.Duration
+}
+
+func (s *slowHandler) ID() string {
+	return "slow"
+}
+
+func (s *slowHandler) Config() interface{} {
+	return nil
+}
+
+func (s *slowHandler) AttachedHandlers() []telemetry.EventRegistrar {
+	return []telemetry.EventRegistrar{
+		{
+			Event: s.event,
+			Handler: func(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+				time.Sleep(s.delay)
+			},
+		},
+	}
+}
+
+func TestTelemetryProcessors(t *testing.T) {
+	t.Run("should run the processors on copies of the maps", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig(
+			telemetry.WithProcessors(
+				func(event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+					metadata["region"] = "eu"
+				},
+				func(event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+					measurement["count"] = measurement["count"].(int) * 2
+					metadata["region"] = metadata["region"].(string) + "-west"
+				},
+			),
+		))
+		mailer := mailbox.ForTest(t, provider, "gopulse.event.processed")
+
+		measurement := map[string]interface{}{"count": 2}
+		metadata := map[string]interface{}{}
+		provider.TriggerEvent("gopulse.event.processed", measurement, metadata)
+
+		if !mailer.AssertReceived("gopulse.event.processed", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Measurement["count"] == 4 && box[0].Metadata["region"] == "eu-west"
+		}) {
+			t.Errorf("should run the processors in order")
+		}
+
+		if measurement["count"] != 2 || len(metadata) != 0 {
+			t.Errorf("should not change the maps of the caller, got %v and %v", measurement, metadata)
+		}
+	})
+
+	t.Run("should dispatch the event when a processor panics", func(t *testing.T) {
+		provider := providers.NewTelemetry(telemetry.NewTelemetryConfig(
+			telemetry.WithProcessors(func(event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+				panic("processor failed")
+			}),
+		))
+		mailer := mailbox.ForTest(t, provider, "gopulse.event.processed")
+
+		provider.TriggerEvent("gopulse.event.processed", map[string]interface{}{}, nil)
+
+		if !mailer.AssertReceived("gopulse.event.processed", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 1
+		}) {
+			t.Errorf("should assert received the event")
+		}
+	})
+}
+
+func TestTelemetryIsolation(t *testing.T) {
+	t.Run("should give every concurrent handler its own maps", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+
+		var corrupted atomic.Int64
+		for i := 0; i < 8; i++ {
+			provider.AddHandlers(&mutatingHandler{id: fmt.Sprintf("mutating-%d", i), event: "gopulse.event.shared", corrupted: &corrupted})
+		}
+
+		measurement := map[string]interface{}{"count": 1}
+		metadata := map[string]interface{}{"owner": "caller"}
+		for i := 0; i < 100; i++ {
+			provider.TriggerEvent("gopulse.event.shared", measurement, metadata)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := provider.(telemetry.Idler).WaitIdle(ctx, 10*time.Millisecond); err != nil {
+			t.Fatalf("should become idle, got %v", err)
+		}
+
+		if corrupted.Load() != 0 {
+			t.Errorf("expected every handler to see the original values, %d saw changes of other handlers", corrupted.Load())
+		}
+		if len(measurement) != 1 || len(metadata) != 1 || metadata["owner"] != "caller" {
+			t.Errorf("should not change the caller's maps, got %v and %v", measurement, metadata)
+		}
+	})
+
+	t.Run("should not change the measurement returned by the span func", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+
+		var corrupted atomic.Int64
+		provider.AddHandlers(&mutatingHandler{id: "mutating", event: "gopulse.event.span.end", corrupted: &corrupted})
+
+		spanMeasurement := map[string]interface{}{"count": 1}
+		provider.TriggerSpan("gopulse.event.span", map[string]interface{}{}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			return nil, nil, spanMeasurement, map[string]interface{}{"owner": "caller"}
+		})
+
+		// the caller may keep using the map while the handlers run
+		spanMeasurement["count"] = 2
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := provider.(telemetry.Idler).WaitIdle(ctx, 10*time.Millisecond); err != nil {
+			t.Fatalf("should become idle, got %v", err)
+		}
+
+		if _, ok := spanMeasurement["duration"]; ok || len(spanMeasurement) != 1 {
+			t.Errorf("should not add the span measurements to the returned map, got %v", spanMeasurement)
+		}
+	})
+}
+
+// a handler that writes to the maps it receives
+type mutatingHandler struct {
+	id        string
+	event     string
+	corrupted *atomic.Int64
+}
+
+func (m *mutatingHandler) ID() string {
+	return m.id
+}
+
+func (m *mutatingHandler) Config() interface{} {
+	return nil
+}
+
+func (m *mutatingHandler) AttachedHandlers() []telemetry.EventRegistrar {
+	return []telemetry.EventRegistrar{
+		{
+			Event: m.event,
+			Handler: func(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+				if _, ok := metadata["handler"]; ok || metadata["owner"] != "caller" {
+					m.corrupted.Add(1)
+				}
+
+				measurement["count"] = 0
+				metadata["owner"] = m.id
+				metadata["handler"] = m.id
+			},
+		},
+	}
+}
+
+// records when it is started and stopped
+type recordingService struct {
+	name   string
+	events *[]string
+}
+
+func (s *recordingService) Start() {
+	*s.events = append(*s.events, s.name+".start")
+}
+
+func (s *recordingService) Stop() {
+	*s.events = append(*s.events, s.name+".stop")
+}
+
+func TestTelemetryLifecycle(t *testing.T) {
+	t.Run("should start the services and stop them in reverse order", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+		lifecycle := provider.(telemetry.Lifecycle)
+
+		events := []string{}
+		lifecycle.StartServices(&recordingService{name: "runtime", events: &events}, &recordingService{name: "queue", events: &events})
+
+		if err := lifecycle.Stop(t.Context()); err != nil {
+			t.Fatalf("stop should succeed: %v", err)
+		}
+		if err := lifecycle.Stop(t.Context()); err != nil {
+			t.Fatalf("stopping twice should be a no-op: %v", err)
+		}
+
+		// a service added once the provider stopped is not started
+		lifecycle.StartServices(&recordingService{name: "late", events: &events})
+
+		expected := fmt.Sprint([]string{"runtime.start", "queue.start", "queue.stop", "runtime.stop"})
+		if fmt.Sprint(events) != expected {
+			t.Errorf("expected %s, got %v", expected, events)
+		}
+	})
+
+	t.Run("should run the queued handlers before stopping", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+		mailer := mailbox.ForTest(t, provider, "gopulse.event.stop")
+
+		for i := 0; i < 50; i++ {
+			provider.TriggerEvent("gopulse.event.stop", map[string]interface{}{}, map[string]interface{}{})
+		}
+
+		if err := provider.(telemetry.Lifecycle).Stop(t.Context()); err != nil {
+			t.Fatalf("stop should succeed: %v", err)
+		}
+
+		if total := mailer.Stats("gopulse.event.stop").Total; total != 50 {
+			t.Errorf("expected the 50 queued events to be handled, got %d", total)
+		}
+	})
+
+	t.Run("should be implemented by scoped views", func(t *testing.T) {
+		provider := newAsyncTelemetry()
+		billing := provider.(telemetry.Scoper).With("billing", nil)
+
+		events := []string{}
+		billing.(telemetry.Lifecycle).StartServices(&recordingService{name: "poller", events: &events})
+		provider.(telemetry.Lifecycle).Stop(t.Context())
+
+		if fmt.Sprint(events) != fmt.Sprint([]string{"poller.start", "poller.stop"}) {
+			t.Errorf("expected the service to be stopped with the provider, got %v", events)
+		}
+	})
+}
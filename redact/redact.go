@@ -0,0 +1,459 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// how a redacted value is replaced
+type Mode int
+
+const (
+	ModeMask Mode = iota // replaced by the mask
+	ModeDrop             // removed, matched substrings of string values are masked
+	ModeHash             // replaced by a hash, so equal values can still be correlated
+)
+
+// replaces redacted values in mask mode
+const Mask = "[REDACTED]"
+
+// keys redacted by default, matched against the key and its dotted path
+var DefaultKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"authorization",
+	"cookie",
+	"set-cookie",
+	"api_key",
+	"apikey",
+	"token",
+	"*.token",
+	"*_token",
+}
+
+// values scrubbed from strings by default
+var DefaultPatterns = []*regexp.Regexp{
+	// email addresses
+	regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+	// bearer tokens
+	regexp.MustCompile(`(?i)bearer\s+[a-zA-Z0-9\-._~+/]+=*`),
+}
+
+// card numbers, 13 to 19 digits optionally separated by spaces or dashes
+var cardPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+
+// redactor option func
+type RedactorOption func(redactor *Redactor)
+
+/*
+A redactor scrubs secrets and personal data from the measurements and
+metadata of every event. register its processor on the provider, after
+the other processors, so no handler ever sees the raw values
+
+	telemetry.WithProcessors(redact.NewRedactor().Processor())
+
+values with a key matching a key rule are redacted whole. string values,
+and the messages of errors, anywhere in the maps have the matches of the
+patterns redacted. nested maps, slices and structs are walked, including
+structs implementing fmt.Stringer or error, as sinks encode their fields. a value is
+only replaced when something in it was redacted: maps and slices by redacted
+copies, and structs by maps keyed by the json names of their exported fields.
+*/
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	cards    bool // scrub card numbers passing the luhn check
+	mode     Mode
+	hashKey  []byte
+}
+
+/*
+Registers a new redactor.
+if no options are provided, the default sets
+keys to DefaultKeys,
+patterns to DefaultPatterns and card numbers,
+mode to ModeMask
+*/
+func NewRedactor(options ...RedactorOption) *Redactor {
+	redactor := &Redactor{
+		keys:     append([]string{}, DefaultKeys...),
+		patterns: append([]*regexp.Regexp{}, DefaultPatterns...),
+		cards:    true,
+		mode:     ModeMask,
+	}
+
+	for _, option := range options {
+		option(redactor)
+	}
+
+	return redactor
+}
+
+// helper functions for setting redactor options
+
+/*
+adds key rules. a rule matches a key by its name or by its path, with the
+keys of nested maps joined by dots, and may use the wildcards of path.Match.
+rules are case insensitive.
+*/
+func WithKeys(keys ...string) RedactorOption {
+	return func(redactor *Redactor) {
+		redactor.keys = append(redactor.keys, keys...)
+	}
+}
+
+// adds patterns whose matches are redacted from string values
+func WithPatterns(patterns ...*regexp.Regexp) RedactorOption {
+	return func(redactor *Redactor) {
+		redactor.patterns = append(redactor.patterns, patterns...)
+	}
+}
+
+// removes the default keys and patterns, so only the added rules apply
+func WithoutDefaults() RedactorOption {
+	return func(redactor *Redactor) {
+		redactor.keys = []string{}
+		redactor.patterns = []*regexp.Regexp{}
+		redactor.cards = false
+	}
+}
+
+// sets how redacted values are replaced
+func WithMode(mode Mode) RedactorOption {
+	return func(redactor *Redactor) {
+		redactor.mode = mode
+	}
+}
+
+// sets the key of the hmac used in hash mode, without it values are hashed with sha256
+func WithHashKey(key []byte) RedactorOption {
+	return func(redactor *Redactor) {
+		redactor.hashKey = key
+	}
+}
+
+// returns the processor redacting every event
+func (r *Redactor) Processor() telemetry.ProcessorFunc {
+	return func(event string, measurement map[string]interface{}, metadata map[string]interface{}) {
+		r.Redact(measurement)
+		r.Redact(metadata)
+	}
+}
+
+/*
+Redacts the values of the map in place.
+nested values are replaced by redacted copies, so values shared with the
+caller are never changed, and values without anything to redact are kept
+as they are. the walk stops at values referencing
+one of their parents and at maxDepth, and leaves those values unchanged.
+*/
+func (r *Redactor) Redact(values map[string]interface{}) {
+	w := &walk{ancestors: make(map[uintptr]bool)}
+
+	for key, value := range values {
+		redacted, keep, changed := r.redactKey(w, key, key, value)
+		if !keep {
+			delete(values, key)
+			continue
+		}
+		if changed {
+			values[key] = redacted
+		}
+	}
+}
+
+// private methods
+
+// the deepest nesting walked, deeper values are left unchanged
+const maxDepth = 32
+
+// the state of the walk through the values of a map
+type walk struct {
+	ancestors map[uintptr]bool // the maps, slices and pointers being walked
+	depth     int
+}
+
+// enters a nested value, false if it is one of its own parents or the walk is too deep
+func (w *walk) enter(pointer uintptr) bool {
+	if w.depth >= maxDepth || w.ancestors[pointer] {
+		return false
+	}
+
+	w.depth++
+	if pointer != 0 {
+		w.ancestors[pointer] = true
+	}
+
+	return true
+}
+
+// leaves the nested value, a value referenced twice but not by itself is still walked twice
+func (w *walk) leave(pointer uintptr) {
+	w.depth--
+	delete(w.ancestors, pointer)
+}
+
+/*
+redacts the value of the key.
+keep is false if the key should be removed, changed is false if the value
+had nothing to redact and is returned as it is.
+*/
+func (r *Redactor) redactKey(w *walk, key string, keyPath string, value interface{}) (redacted interface{}, keep bool, changed bool) {
+	if r.matchesKey(key, keyPath) {
+		switch r.mode {
+		case ModeDrop:
+			return nil, false, true
+		case ModeHash:
+			return r.hash(fmt.Sprint(value)), true, true
+		default:
+			return Mask, true, true
+		}
+	}
+
+	redacted, changed = r.redactValue(w, keyPath, value)
+	return redacted, true, changed
+}
+
+// walks the value and redacts the strings and nested keys, changed is false if nothing was redacted
+func (r *Redactor) redactValue(w *walk, keyPath string, value interface{}) (interface{}, bool) {
+	switch typed := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		scrubbed := r.scrub(typed)
+		return scrubbed, scrubbed != typed
+	case time.Time:
+		return typed, false
+	case error:
+		// errors are only replaced by their message when something was redacted in it,
+		// otherwise their fields are walked like any other struct
+		message := typed.Error()
+		if scrubbed := r.scrub(message); scrubbed != message {
+			return scrubbed, true
+		}
+	}
+
+	redacted, changed := r.redactReflected(w, keyPath, reflect.ValueOf(value))
+	if !changed {
+		return value, false
+	}
+
+	return redacted, true
+}
+
+// returns a redacted copy of the map, changed is false if nothing was redacted
+func (r *Redactor) redactMap(w *walk, keyPath string, values map[string]interface{}) (map[string]interface{}, bool) {
+	redacted := make(map[string]interface{}, len(values))
+	changed := false
+	for key, value := range values {
+		value, keep, valueChanged := r.redactKey(w, key, keyPath+"."+key, value)
+		changed = changed || valueChanged
+		if keep {
+			redacted[key] = value
+		}
+	}
+
+	return redacted, changed
+}
+
+/*
+converts structs, maps and slices holding strings so they can be walked.
+the converted value is only returned when something in it was redacted.
+*/
+func (r *Redactor) redactReflected(w *walk, keyPath string, value reflect.Value) (interface{}, bool) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct {
+			return nil, false
+		}
+		if !w.enter(value.Pointer()) {
+			return nil, false
+		}
+		defer w.leave(value.Pointer())
+
+		return r.redactReflected(w, keyPath, value.Elem())
+	case reflect.Struct:
+		if !w.enter(0) {
+			return nil, false
+		}
+		defer w.leave(0)
+
+		return r.redactMap(w, keyPath, structMap(value))
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String || !holdsStrings(value.Type().Elem()) {
+			return nil, false
+		}
+		if !w.enter(value.Pointer()) {
+			return nil, false
+		}
+		defer w.leave(value.Pointer())
+
+		converted := make(map[string]interface{}, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			converted[iterator.Key().String()] = iterator.Value().Interface()
+		}
+		return r.redactMap(w, keyPath, converted)
+	case reflect.Slice, reflect.Array:
+		if !holdsStrings(value.Type().Elem()) {
+			return nil, false
+		}
+
+		// arrays are copied by value so they can not reference themselves
+		pointer := uintptr(0)
+		if value.Kind() == reflect.Slice {
+			pointer = value.Pointer()
+		}
+		if !w.enter(pointer) {
+			return nil, false
+		}
+		defer w.leave(pointer)
+
+		converted := make([]interface{}, value.Len())
+		changed := false
+		for i := range converted {
+			item, itemChanged := r.redactValue(w, keyPath, value.Index(i).Interface())
+			converted[i] = item
+			changed = changed || itemChanged
+		}
+		return converted, changed
+	case reflect.String:
+		scrubbed := r.scrub(value.String())
+		return scrubbed, scrubbed != value.String()
+	default:
+		return nil, false
+	}
+}
+
+// is the key or its path matched by a key rule?
+func (r *Redactor) matchesKey(key string, keyPath string) bool {
+	key = strings.ToLower(key)
+	keyPath = strings.ToLower(keyPath)
+
+	for _, rule := range r.keys {
+		rule = strings.ToLower(rule)
+		if rule == key || rule == keyPath {
+			return true
+		}
+		if matched, _ := path.Match(rule, keyPath); matched {
+			return true
+		}
+		if matched, _ := path.Match(rule, key); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redacts the matches of the patterns in the value
+func (r *Redactor) scrub(value string) string {
+	for _, pattern := range r.patterns {
+		value = pattern.ReplaceAllStringFunc(value, r.replace)
+	}
+
+	// numbers such as timestamps look like card numbers, so they must pass the luhn check
+	if r.cards {
+		value = cardPattern.ReplaceAllStringFunc(value, func(match string) string {
+			if !luhn(match) {
+				return match
+			}
+			return r.replace(match)
+		})
+	}
+
+	return value
+}
+
+// returns the replacement of a matched substring
+func (r *Redactor) replace(match string) string {
+	if r.mode == ModeHash {
+		return r.hash(match)
+	}
+
+	return Mask
+}
+
+// returns a short hash of the value
+func (r *Redactor) hash(value string) string {
+	var sum []byte
+	if r.hashKey != nil {
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		sum = mac.Sum(nil)
+	} else {
+		hashed := sha256.Sum256([]byte(value))
+		sum = hashed[:]
+	}
+
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// converts the exported fields of the struct to a map keyed by their json names
+func structMap(value reflect.Value) map[string]interface{} {
+	fields := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields[name] = value.Field(i).Interface()
+	}
+
+	return fields
+}
+
+// can values of the type hold strings that should be scrubbed?
+func holdsStrings(valueType reflect.Type) bool {
+	switch valueType.Kind() {
+	case reflect.String, reflect.Struct, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Pointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// does the number pass the luhn checksum of card numbers?
+func luhn(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		if number[i] < '0' || number[i] > '9' {
+			continue
+		}
+
+		digit := int(number[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
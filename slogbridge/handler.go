@@ -0,0 +1,152 @@
+package slogbridge
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// handler option func
+type HandlerOption func(handler *Handler)
+
+/*
+A slog handler that triggers a gopulse event for every log record.
+the event is {event}.{level}, such as log.info or log.error. the level and
+message are added to the metadata along with the attributes, which are
+flattened with their groups joined by dots. the time of the record is the
+timestamp measurement, omitted for records without a time.
+*/
+type Handler struct {
+	provider telemetry.TelemetryInterface
+	event    string
+	level    slog.Leveler
+	attrs    []slog.Attr // attributes added with WithAttrs, already qualified by their groups
+	groups   []string    // groups opened with WithGroup
+}
+
+func NewHandler(provider telemetry.TelemetryInterface, options ...HandlerOption) *Handler {
+	handler := &Handler{
+		provider: provider,
+		event:    "log",
+		level:    slog.LevelInfo,
+		attrs:    make([]slog.Attr, 0),
+		groups:   make([]string, 0),
+	}
+
+	for _, option := range options {
+		option(handler)
+	}
+
+	return handler
+}
+
+// helper functions for setting handler options
+
+// sets the base event the level is appended to
+func WithEvent(event string) HandlerOption {
+	return func(handler *Handler) {
+		handler.event = event
+	}
+}
+
+// sets the minimum level of the records turned into events
+func WithLevel(level slog.Leveler) HandlerOption {
+	return func(handler *Handler) {
+		handler.level = level
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	metadata := make(map[string]interface{}, record.NumAttrs()+len(h.attrs)+2)
+	for _, attr := range h.attrs {
+		flatten(metadata, "", attr)
+	}
+
+	prefix := strings.Join(h.groups, ".")
+	record.Attrs(func(attr slog.Attr) bool {
+		flatten(metadata, prefix, attr)
+		return true
+	})
+
+	metadata["level"] = record.Level.String()
+	metadata["message"] = record.Message
+
+	// a record built by hand may have no time, which slog handlers ignore
+	measurement := map[string]interface{}{}
+	if !record.Time.IsZero() {
+		measurement["timestamp"] = record.Time.UnixMilli()
+	}
+
+	return h.provider.TriggerEvent(h.event+"."+strings.ToLower(record.Level.String()), measurement, metadata)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := h.clone()
+
+	// qualify the attributes by the groups open when they were added
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr = slog.Attr{Key: prefix + "." + attr.Key, Value: attr.Value}
+		}
+		handler.attrs = append(handler.attrs, attr)
+	}
+
+	return handler
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := h.clone()
+	handler.groups = append(handler.groups, name)
+
+	return handler
+}
+
+// private methods
+
+func (h *Handler) clone() *Handler {
+	return &Handler{
+		provider: h.provider,
+		event:    h.event,
+		level:    h.level,
+		attrs:    append([]slog.Attr{}, h.attrs...),
+		groups:   append([]string{}, h.groups...),
+	}
+}
+
+// add the attribute to the metadata, groups are flattened into dotted keys
+func flatten(metadata map[string]interface{}, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+
+	// empty attributes are ignored as required by slog.Handler
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	key := attr.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		// inline groups without a key share the prefix
+		key = prefix
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, groupAttr := range attr.Value.Group() {
+			flatten(metadata, key, groupAttr)
+		}
+		return
+	}
+
+	metadata[key] = attr.Value.Any()
+}
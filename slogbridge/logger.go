@@ -0,0 +1,86 @@
+package slogbridge
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+/*
+A gopulse handler that writes events to a slog logger.
+the measurement and metadata are written as the measurement and metadata
+groups of structured attributes, the message is the event name.
+*/
+type EventLogger struct {
+	id       string
+	logger   *slog.Logger
+	handlers []telemetry.EventRegistrar
+}
+
+// event logger will implement the telemetry handler interface
+
+func NewEventLogger(id string, logger *slog.Logger) *EventLogger {
+	return &EventLogger{
+		id:       id,
+		logger:   logger,
+		handlers: make([]telemetry.EventRegistrar, 0),
+	}
+}
+
+// logs the events at the level
+func (l *EventLogger) Log(level slog.Level, events ...string) *EventLogger {
+	for _, event := range events {
+		l.handlers = append(l.handlers, telemetry.EventRegistrar{
+			Event:   event,
+			Handler: l.handleEvent(level),
+		})
+	}
+
+	return l
+}
+
+func (l *EventLogger) ID() string {
+	return l.id
+}
+
+func (l *EventLogger) Config() interface{} {
+	return nil
+}
+
+func (l *EventLogger) AttachedHandlers() []telemetry.EventRegistrar {
+	return l.handlers
+}
+
+// private methods
+
+func (l *EventLogger) handleEvent(level slog.Level) telemetry.HandleEventFunc {
+	return func(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+		ctx := context.Background()
+		if !l.logger.Enabled(ctx, level) {
+			return
+		}
+
+		l.logger.LogAttrs(ctx, level, event,
+			slog.Attr{Key: "measurement", Value: slog.GroupValue(attrs(measurement)...)},
+			slog.Attr{Key: "metadata", Value: slog.GroupValue(attrs(metadata)...)},
+		)
+	}
+}
+
+// converts the values to attributes ordered by key
+func attrs(values map[string]interface{}) []slog.Attr {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	converted := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		converted = append(converted, slog.Any(key, values[key]))
+	}
+
+	return converted
+}
@@ -0,0 +1,116 @@
+package slogbridge_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+	"github.com/trexreigns/gopulse/slogbridge"
+)
+
+func TestHandler(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	t.Run("should trigger an event per record", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry, "log.info", "log.error", "log.debug")
+		logger := slog.New(slogbridge.NewHandler(telemetry))
+
+		logger.Info("user created", "user_id", 42, slog.Group("request", "method", "POST", "path", "/users"))
+		logger.Error("payment failed", "amount", 9.99)
+		logger.Debug("below the level")
+
+		if !mailer.AssertReceived("log.info", func(event string, box ...mailbox.MailData) bool {
+			metadata := box[0].Metadata
+			return len(box) == 1 &&
+				metadata["level"] == "INFO" &&
+				metadata["message"] == "user created" &&
+				metadata["user_id"] == int64(42) &&
+				metadata["request.method"] == "POST" &&
+				metadata["request.path"] == "/users" &&
+				box[0].Measurement["timestamp"] != nil
+		}) {
+			t.Errorf("should assert received the info record")
+		}
+
+		if !mailer.AssertReceived("log.error", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["amount"] == 9.99
+		}) {
+			t.Errorf("should assert received the error record")
+		}
+
+		if !mailer.RefuteReceived("log.debug", func(event string, box ...mailbox.MailData) bool {
+			return true
+		}) {
+			t.Errorf("should not trigger events below the level")
+		}
+	})
+
+	t.Run("should qualify attributes by their groups", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry, "app.warn")
+		logger := slog.New(slogbridge.NewHandler(telemetry, slogbridge.WithEvent("app"), slogbridge.WithLevel(slog.LevelDebug)))
+
+		logger.With("service", "billing").WithGroup("job").With("id", 7).Warn("retrying", "attempt", 2)
+
+		if !mailer.AssertReceived("app.warn", func(event string, box ...mailbox.MailData) bool {
+			metadata := box[0].Metadata
+			return metadata["service"] == "billing" &&
+				metadata["job.id"] == int64(7) &&
+				metadata["job.attempt"] == int64(2)
+		}) {
+			t.Errorf("should assert received the grouped attributes")
+		}
+	})
+
+	t.Run("should omit the timestamp of a record without a time", func(t *testing.T) {
+		mailer := mailbox.ForTest(t, telemetry, "log.info")
+		handler := slogbridge.NewHandler(telemetry)
+
+		if err := handler.Handle(t.Context(), slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0)); err != nil {
+			t.Fatalf("handle should succeed: %v", err)
+		}
+
+		if !mailer.AssertReceived("log.info", func(event string, box ...mailbox.MailData) bool {
+			_, ok := box[0].Measurement["timestamp"]
+			return len(box) == 1 && !ok
+		}) {
+			t.Errorf("should not measure the timestamp of a record without a time")
+		}
+	})
+}
+
+func TestEventLogger(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	var output bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&output, nil))
+
+	eventLogger := slogbridge.NewEventLogger("slog", logger).
+		Log(slog.LevelInfo, "gopulse.event.test").
+		Log(slog.LevelDebug, "gopulse.event.debug")
+	telemetry.AddHandlers(eventLogger)
+
+	telemetry.TriggerEvent("gopulse.event.test", map[string]interface{}{"count": 3}, map[string]interface{}{"result": "ok"})
+	telemetry.TriggerEvent("gopulse.event.debug", map[string]interface{}{}, map[string]interface{}{})
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(output.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single json record, got %s", output.String())
+	}
+
+	if record["msg"] != "gopulse.event.test" || record["level"] != "INFO" {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	measurement := record["measurement"].(map[string]interface{})
+	metadata := record["metadata"].(map[string]interface{})
+	if measurement["count"] != float64(3) || metadata["result"] != "ok" {
+		t.Errorf("expected structured attributes, got %v", record)
+	}
+}
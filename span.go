@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+/*
+measurement keys TriggerSpan adds to the start, end and panic events of a span.
+the ids are generated unless the metadata passed to TriggerSpan has them as
+strings, which lets a span continue a trace started in another process.
+TriggerSpan takes no context, so a span started inside another span is not
+linked to it: without seeded ids it starts a new trace with no parent.
+seed the child with traceparent.Seed to link it.
+*/
+const (
+	SpanIDKey       = "span_id"        // identifies the span, shared by its start, end and panic events
+	TraceIDKey      = "trace_id"       // identifies the trace the span belongs to
+	ParentSpanIDKey = "parent_span_id" // identifies the parent of the span, only added when seeded
+)
+
+// suffixes of the events TriggerSpan emits for a span, appended to its base event
+const (
+	SpanStartSuffix = ".start"
+	SpanEndSuffix   = ".end"
+	SpanPanicSuffix = ".panic"
+)
+
+// returns the span id of a start, end or panic event, used to pair the events of a span
+func SpanID(measurement map[string]interface{}) (string, bool) {
+	spanID, ok := measurement[SpanIDKey].(string)
+	return spanID, ok
+}
+
+// returns a random 8 byte span id encoded as hex
+func NewSpanID() string {
+	return randomHex(8)
+}
+
+// returns a random 16 byte trace id encoded as hex
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+func randomHex(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,222 @@
+package spans
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// exports a batch of finished spans
+type ExportFunc func(ctx context.Context, batch []Span) error
+
+// batcher option func
+type BatcherOption func(batcher *Batcher)
+
+// defaults of the batcher, also used for options that are not positive
+const (
+	DefaultBatchSize     = 512
+	DefaultMaxQueueSize  = 2048
+	DefaultFlushInterval = 5 * time.Second
+)
+
+/*
+A batcher queues finished spans and exports them in batches.
+the queue is exported on every flush interval and as soon as a batch is
+full. spans are dropped when the queue is full or their export failed.
+exporters pass its Add method to NewTracker as the finish func.
+*/
+type Batcher struct {
+	export        ExportFunc
+	flushFunc     func(ctx context.Context) error
+	batchSize     int
+	maxQueueSize  int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	queue   []Span
+	dropped atomic.Uint64 // spans dropped because the queue was full or the export failed
+
+	ctx      context.Context // the context of the exports of the flush loop, canceled on shutdown
+	cancel   context.CancelFunc
+	flush    chan struct{}
+	done     chan struct{}
+	stopped  sync.WaitGroup
+	stopOnce sync.Once
+}
+
+/*
+Registers a new batcher and starts its flush loop.
+if no options are provided, the default sets
+batchSize to DefaultBatchSize,
+maxQueueSize to DefaultMaxQueueSize,
+flushInterval to DefaultFlushInterval,
+flushFunc to none
+*/
+func NewBatcher(export ExportFunc, options ...BatcherOption) *Batcher {
+	batcher := &Batcher{
+		export:        export,
+		batchSize:     DefaultBatchSize,
+		maxQueueSize:  DefaultMaxQueueSize,
+		flushInterval: DefaultFlushInterval,
+		queue:         make([]Span, 0),
+		flush:         make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(batcher)
+	}
+
+	if batcher.batchSize <= 0 {
+		batcher.batchSize = DefaultBatchSize
+	}
+	if batcher.maxQueueSize <= 0 {
+		batcher.maxQueueSize = DefaultMaxQueueSize
+	}
+	if batcher.flushInterval <= 0 {
+		batcher.flushInterval = DefaultFlushInterval
+	}
+
+	batcher.ctx, batcher.cancel = context.WithCancel(context.Background())
+	batcher.stopped.Add(1)
+	go batcher.flushLoop()
+
+	return batcher
+}
+
+// helper functions for setting batcher options
+
+// sets the number of spans exported together
+func WithBatchSize(batchSize int) BatcherOption {
+	return func(batcher *Batcher) {
+		batcher.batchSize = batchSize
+	}
+}
+
+// sets the number of finished spans held before new ones are dropped
+func WithMaxQueueSize(maxQueueSize int) BatcherOption {
+	return func(batcher *Batcher) {
+		batcher.maxQueueSize = maxQueueSize
+	}
+}
+
+// sets how often the queue is exported
+func WithFlushInterval(flushInterval time.Duration) BatcherOption {
+	return func(batcher *Batcher) {
+		batcher.flushInterval = flushInterval
+	}
+}
+
+// sets a func called on every flush after the spans are exported, such as exporting metrics
+func WithFlushFunc(flushFunc func(ctx context.Context) error) BatcherOption {
+	return func(batcher *Batcher) {
+		batcher.flushFunc = flushFunc
+	}
+}
+
+// queues the finished span, exporting the queue once a batch is full
+func (b *Batcher) Add(span Span) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.queue) >= b.maxQueueSize {
+		b.dropped.Add(1)
+		return
+	}
+
+	b.queue = append(b.queue, span)
+	if len(b.queue) >= b.batchSize {
+		select {
+		case b.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// returns the number of spans dropped
+func (b *Batcher) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// exports the queued spans and calls the flush func
+func (b *Batcher) Flush(ctx context.Context) error {
+	exportErr := b.exportQueue(ctx)
+
+	if b.flushFunc != nil {
+		if err := b.flushFunc(ctx); err != nil && exportErr == nil {
+			return err
+		}
+	}
+
+	return exportErr
+}
+
+/*
+Stops the flush loop and flushes everything still queued.
+an export of the flush loop still running is canceled once the context is
+done, and the context error is returned without flushing the queue.
+*/
+func (b *Batcher) Shutdown(ctx context.Context) error {
+	var err error
+	b.stopOnce.Do(func() {
+		close(b.done)
+		defer b.cancel()
+
+		stopped := make(chan struct{})
+		go func() {
+			b.stopped.Wait()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			err = b.Flush(ctx)
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	})
+
+	return err
+}
+
+// private methods
+
+func (b *Batcher) flushLoop() {
+	defer b.stopped.Done()
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			b.Flush(b.ctx)
+		case <-b.flush:
+			b.exportQueue(b.ctx)
+		}
+	}
+}
+
+// exports the queue in batches, the spans of a failed batch are dropped
+func (b *Batcher) exportQueue(ctx context.Context) error {
+	b.mu.Lock()
+	queue := b.queue
+	b.queue = make([]Span, 0)
+	b.mu.Unlock()
+
+	var exportErr error
+	for start := 0; start < len(queue); start += b.batchSize {
+		end := min(start+b.batchSize, len(queue))
+		batch := queue[start:end]
+
+		if err := b.export(ctx, batch); err != nil {
+			b.dropped.Add(uint64(len(batch)))
+			exportErr = err
+		}
+	}
+
+	return exportErr
+}
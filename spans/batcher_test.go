@@ -0,0 +1,164 @@
+package spans_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/trexreigns/gopulse/spans"
+)
+
+// records the exported batches
+type exporter struct {
+	mu      sync.Mutex
+	batches [][]spans.Span
+	err     error
+}
+
+func (e *exporter) export(ctx context.Context, batch []spans.Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.batches = append(e.batches, batch)
+	return e.err
+}
+
+func (e *exporter) exported() [][]spans.Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([][]spans.Span{}, e.batches...)
+}
+
+func newSpan(i int) spans.Span {
+	return spans.Span{Name: "gopulse.job", SpanID: fmt.Sprint(i)}
+}
+
+func TestBatcher(t *testing.T) {
+	t.Run("should export a full batch without waiting for the flush interval", func(t *testing.T) {
+		exporter := &exporter{}
+		batcher := spans.NewBatcher(exporter.export, spans.WithBatchSize(3), spans.WithFlushInterval(time.Hour))
+		defer batcher.Shutdown(context.Background())
+
+		for i := 0; i < 3; i++ {
+			batcher.Add(newSpan(i))
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for len(exporter.exported()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		if batches := exporter.exported(); len(batches) != 1 || len(batches[0]) != 3 {
+			t.Errorf("expected a single batch of 3 spans, got %v", batches)
+		}
+	})
+
+	t.Run("should export the queue on the flush interval", func(t *testing.T) {
+		exporter := &exporter{}
+		var flushed atomic.Int64
+		batcher := spans.NewBatcher(exporter.export, spans.WithFlushInterval(10*time.Millisecond), spans.WithFlushFunc(func(ctx context.Context) error {
+			flushed.Add(1)
+			return nil
+		}))
+		defer batcher.Shutdown(context.Background())
+
+		batcher.Add(newSpan(1))
+
+		deadline := time.Now().Add(time.Second)
+		for len(exporter.exported()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		if batches := exporter.exported(); len(batches) != 1 || len(batches[0]) != 1 {
+			t.Errorf("expected the span to be exported on the interval, got %v", batches)
+		}
+		if flushed.Load() == 0 {
+			t.Errorf("expected the flush func to be called on the interval")
+		}
+	})
+
+	t.Run("should fall back to the defaults and split the queue into batches on shutdown", func(t *testing.T) {
+		exporter := &exporter{}
+		batcher := spans.NewBatcher(exporter.export, spans.WithBatchSize(0), spans.WithFlushInterval(0), spans.WithMaxQueueSize(0))
+
+		for i := 0; i < 7; i++ {
+			batcher.Add(newSpan(i))
+		}
+		if err := batcher.Shutdown(context.Background()); err != nil {
+			t.Fatalf("shutdown should succeed: %v", err)
+		}
+
+		total := 0
+		for _, batch := range exporter.exported() {
+			total += len(batch)
+		}
+		if total != 7 {
+			t.Errorf("expected 7 spans exported, got %d", total)
+		}
+	})
+
+	t.Run("should count the spans dropped", func(t *testing.T) {
+		exporter := &exporter{err: errors.New("collector is down")}
+		batcher := spans.NewBatcher(exporter.export, spans.WithMaxQueueSize(2), spans.WithFlushInterval(time.Hour))
+
+		for i := 0; i < 3; i++ {
+			batcher.Add(newSpan(i))
+		}
+
+		// one span over the queue size and two failing to export
+		if err := batcher.Shutdown(context.Background()); err == nil {
+			t.Errorf("expected the export error")
+		}
+		if dropped := batcher.Dropped(); dropped != 3 {
+			t.Errorf("expected 3 dropped spans, got %d", dropped)
+		}
+	})
+
+	t.Run("should return the flush func error", func(t *testing.T) {
+		exporter := &exporter{}
+		flushErr := errors.New("metrics failed")
+		batcher := spans.NewBatcher(exporter.export, spans.WithFlushInterval(time.Hour), spans.WithFlushFunc(func(ctx context.Context) error {
+			return flushErr
+		}))
+
+		if err := batcher.Shutdown(context.Background()); !errors.Is(err, flushErr) {
+			t.Errorf("expected the flush func error, got %v", err)
+		}
+	})
+
+	t.Run("should stop waiting for an export once the context is done", func(t *testing.T) {
+		started := make(chan struct{})
+		canceled := make(chan struct{})
+		batcher := spans.NewBatcher(func(ctx context.Context, batch []spans.Span) error {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		}, spans.WithBatchSize(1), spans.WithFlushInterval(time.Hour))
+
+		batcher.Add(newSpan(1))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		shutdownStart := time.Now()
+		if err := batcher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the deadline error, got %v", err)
+		}
+		if elapsed := time.Since(shutdownStart); elapsed > 500*time.Millisecond {
+			t.Errorf("expected shutdown to return at the deadline, took %v", elapsed)
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Errorf("expected the running export to be canceled")
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package spans
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// a span rebuilt from the start and end or panic events of TriggerSpan
+type Span struct {
+	Name          string                 // the base event of the span
+	TraceID       string                 // the trace id of the span
+	SpanID        string                 // the span id of the span
+	ParentSpanID  string                 // the span id of the parent, empty for a root span
+	Start         time.Time              // when the span started
+	End           time.Time              // when the span ended or panicked
+	StartMetadata map[string]interface{} // metadata of the start event
+	EndMetadata   map[string]interface{} // metadata of the end event, nil if the span panicked
+	Measurement   map[string]interface{} // measurement of the end event, nil if the span panicked
+	Panicked      bool                   // did the span panic?
+	Error         interface{}            // the recovered panic
+	StackTrace    string                 // the stack trace of the panic
+}
+
+// returns how long the span ran
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// returns the start and end metadata merged, the end metadata wins
+func (s Span) Metadata() map[string]interface{} {
+	metadata := make(map[string]interface{}, len(s.StartMetadata)+len(s.EndMetadata))
+	for key, value := range s.StartMetadata {
+		metadata[key] = value
+	}
+	for key, value := range s.EndMetadata {
+		metadata[key] = value
+	}
+
+	return metadata
+}
+
+// func called with every finished span
+type FinishFunc func(span Span)
+
+// defaults bounding the spans waiting for their other half
+const (
+	DefaultMaxPending = 10000
+	DefaultPendingTTL = 10 * time.Minute
+)
+
+/*
+A tracker pairs the start events of spans with their end or panic events
+using the span id TriggerSpan adds to their measurements.
+exporters embed its handlers and receive every finished span.
+
+a span whose other half never arrives, because the tracker was attached
+mid span or the pool dropped the event, is evicted once it has waited for
+the pending ttl or when more than max pending spans are waiting.
+*/
+type Tracker struct {
+	events     []string
+	finish     FinishFunc
+	pending    map[string]*pendingSpan
+	order      []pendingEntry // pending span ids by arrival, may hold ids already finished
+	maxPending int
+	pendingTTL time.Duration
+	evicted    atomic.Uint64
+	mu         sync.Mutex
+}
+
+func NewTracker(finish FinishFunc, events ...string) *Tracker {
+	return &Tracker{
+		events:     events,
+		finish:     finish,
+		pending:    make(map[string]*pendingSpan),
+		order:      make([]pendingEntry, 0),
+		maxPending: DefaultMaxPending,
+		pendingTTL: DefaultPendingTTL,
+		mu:         sync.Mutex{},
+	}
+}
+
+// sets the most spans waiting for their other half, the oldest are evicted first
+func (t *Tracker) WithMaxPending(maxPending int) *Tracker {
+	t.maxPending = maxPending
+	return t
+}
+
+// sets how long a span waits for its other half before it is evicted
+func (t *Tracker) WithPendingTTL(pendingTTL time.Duration) *Tracker {
+	t.pendingTTL = pendingTTL
+	return t
+}
+
+// returns the handlers of the start, end and panic events of every span
+func (t *Tracker) Handlers() []telemetry.EventRegistrar {
+	registrars := make([]telemetry.EventRegistrar, 0, len(t.events)*3)
+	for _, event := range t.events {
+		registrars = append(registrars,
+			telemetry.EventRegistrar{Event: event + telemetry.SpanStartSuffix, Handler: t.handleStart(event)},
+			telemetry.EventRegistrar{Event: event + telemetry.SpanEndSuffix, Handler: t.handleEnd},
+			telemetry.EventRegistrar{Event: event + telemetry.SpanPanicSuffix, Handler: t.handlePanic},
+		)
+	}
+
+	return registrars
+}
+
+// returns the spans with only their start or their end received
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.pending)
+}
+
+// returns the spans evicted before their other half arrived
+func (t *Tracker) Evicted() uint64 {
+	return t.evicted.Load()
+}
+
+// private methods
+
+// half of a span received before its other half
+type pendingSpan struct {
+	span     Span
+	started  bool
+	finished bool
+}
+
+// when a pending span arrived
+type pendingEntry struct {
+	spanID   string
+	received time.Time
+}
+
+func (t *Tracker) handleStart(name string) telemetry.HandleEventFunc {
+	return func(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+		t.merge(measurement, true, func(span *Span) {
+			span.Name = name
+			span.TraceID, _ = measurement[telemetry.TraceIDKey].(string)
+			span.ParentSpanID, _ = measurement[telemetry.ParentSpanIDKey].(string)
+			span.Start = millisTime(measurement["start_time"])
+			span.StartMetadata = metadata
+		})
+	}
+}
+
+func (t *Tracker) handleEnd(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+	t.merge(measurement, false, func(span *Span) {
+		span.End = millisTime(measurement["end_time"])
+		span.EndMetadata = metadata
+		span.Measurement = measurement
+	})
+}
+
+func (t *Tracker) handlePanic(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+	t.merge(measurement, false, func(span *Span) {
+		span.End = millisTime(metadata["errorTime"])
+		span.Panicked = true
+		span.Error = metadata["error"]
+		span.StackTrace = fmt.Sprint(metadata["stackTrace"])
+	})
+}
+
+/*
+Applies the event to the pending span with the same span id.
+handlers may run out of order when the provider runs concurrently,
+so the span is finished once both its start and its end have arrived.
+*/
+func (t *Tracker) merge(measurement map[string]interface{}, start bool, apply func(span *Span)) {
+	spanID, ok := telemetry.SpanID(measurement)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	pending, ok := t.pending[spanID]
+	if !ok {
+		now := time.Now()
+		pending = &pendingSpan{span: Span{SpanID: spanID}}
+		t.pending[spanID] = pending
+		t.order = append(t.order, pendingEntry{spanID: spanID, received: now})
+		t.evict(now)
+	}
+
+	apply(&pending.span)
+	if start {
+		pending.started = true
+	} else {
+		pending.finished = true
+	}
+
+	complete := pending.started && pending.finished
+	if complete {
+		delete(t.pending, spanID)
+	}
+	t.mu.Unlock()
+
+	if complete {
+		t.finish(pending.span)
+	}
+}
+
+/*
+Evicts the spans that waited longer than the ttl, then the oldest spans
+until no more than max pending are waiting. must be called with the lock held.
+*/
+func (t *Tracker) evict(now time.Time) {
+	evicted := 0
+	for evicted < len(t.order) {
+		entry := t.order[evicted]
+
+		// the span finished after it was queued
+		if _, ok := t.pending[entry.spanID]; !ok {
+			evicted++
+			continue
+		}
+
+		if now.Sub(entry.received) < t.pendingTTL && len(t.pending) <= t.maxPending {
+			break
+		}
+
+		delete(t.pending, entry.spanID)
+		t.evicted.Add(1)
+		evicted++
+	}
+
+	// drop the evicted entries without keeping the old array alive
+	if evicted > 0 {
+		t.order = append(make([]pendingEntry, 0, len(t.order)-evicted), t.order[evicted:]...)
+	}
+
+	// a long running span at the front keeps the finished spans behind it queued
+	if len(t.order) > 2*len(t.pending)+64 {
+		waiting := make([]pendingEntry, 0, 2*len(t.pending))
+		for _, entry := range t.order {
+			if _, ok := t.pending[entry.spanID]; ok {
+				waiting = append(waiting, entry)
+			}
+		}
+		t.order = waiting
+	}
+}
+
+// converts the unix milliseconds TriggerSpan measures to a time
+func millisTime(value interface{}) time.Time {
+	millis, ok := value.(int64)
+	if !ok {
+		return time.Now()
+	}
+
+	return time.UnixMilli(millis)
+}
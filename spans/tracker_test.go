@@ -0,0 +1,198 @@
+package spans_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/providers"
+	"github.com/trexreigns/gopulse/spans"
+)
+
+// handler attaching the handlers of a tracker
+type trackerHandler struct {
+	tracker *spans.Tracker
+}
+
+func (h *trackerHandler) ID() string {
+	return "tracker"
+}
+
+func (h *trackerHandler) Config() interface{} {
+	return nil
+}
+
+func (h *trackerHandler) AttachedHandlers() []telemetry.EventRegistrar {
+	return h.tracker.Handlers()
+}
+
+// records the finished spans
+type recorder struct {
+	mu    sync.Mutex
+	spans []spans.Span
+}
+
+func (r *recorder) finish(span spans.Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans = append(r.spans, span)
+}
+
+func (r *recorder) finished() []spans.Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]spans.Span{}, r.spans...)
+}
+
+func TestTracker(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+	recorder := &recorder{}
+	tracker := spans.NewTracker(recorder.finish, "gopulse.tracked")
+	telemetry.AddHandlers(&trackerHandler{tracker: tracker})
+
+	t.Run("should pair the start and end of a span", func(t *testing.T) {
+		telemetry.TriggerSpan("gopulse.tracked", map[string]interface{}{"user": "42"}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			return nil, nil, map[string]interface{}{"rows": 3}, map[string]interface{}{"result": "ok"}
+		})
+
+		finished := recorder.finished()
+		if len(finished) != 1 {
+			t.Fatalf("expected 1 finished span, got %d", len(finished))
+		}
+
+		span := finished[0]
+		if span.Name != "gopulse.tracked" || span.SpanID == "" || span.TraceID == "" || span.Panicked {
+			t.Errorf("unexpected span %+v", span)
+		}
+
+		metadata := span.Metadata()
+		if metadata["user"] != "42" || metadata["result"] != "ok" || span.Measurement["rows"] != 3 {
+			t.Errorf("unexpected span data %+v", span)
+		}
+
+		if span.End.Before(span.Start) {
+			t.Errorf("span should not end before it starts")
+		}
+
+		if tracker.Pending() != 0 {
+			t.Errorf("expected no pending spans, got %d", tracker.Pending())
+		}
+	})
+
+	t.Run("should record a panicking span", func(t *testing.T) {
+		func() {
+			defer func() {
+				recover()
+			}()
+
+			telemetry.TriggerSpan("gopulse.tracked", map[string]interface{}{}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+				panic(errors.New("boom"))
+			})
+		}()
+
+		finished := recorder.finished()
+		span := finished[len(finished)-1]
+		if !span.Panicked || span.StackTrace == "" {
+			t.Errorf("expected a panicked span, got %+v", span)
+		}
+
+		if err, ok := span.Error.(error); !ok || err.Error() != "boom" {
+			t.Errorf("expected the recovered error, got %v", span.Error)
+		}
+	})
+}
+
+func TestTrackerOutOfOrder(t *testing.T) {
+	recorder := &recorder{}
+	tracker := spans.NewTracker(recorder.finish, "gopulse.tracked")
+
+	handlers := map[string]telemetry.HandleEventFunc{}
+	for _, registrar := range tracker.Handlers() {
+		handlers[registrar.Event] = registrar.Handler
+	}
+
+	// the end arrives before the start, as it may in concurrent mode
+	handlers["gopulse.tracked.end"]("gopulse.tracked.end", map[string]interface{}{
+		telemetry.SpanIDKey: "span",
+		"end_time":          int64(2000),
+	}, map[string]interface{}{}, nil)
+
+	if len(recorder.finished()) != 0 || tracker.Pending() != 1 {
+		t.Fatalf("span should wait for its start")
+	}
+
+	handlers["gopulse.tracked.start"]("gopulse.tracked.start", map[string]interface{}{
+		telemetry.SpanIDKey:  "span",
+		telemetry.TraceIDKey: "trace",
+		"start_time":         int64(1000),
+	}, map[string]interface{}{}, nil)
+
+	finished := recorder.finished()
+	if len(finished) != 1 || finished[0].Duration().Milliseconds() != 1000 || finished[0].TraceID != "trace" {
+		t.Errorf("unexpected spans %+v", finished)
+	}
+}
+
+func TestTrackerEviction(t *testing.T) {
+	t.Run("should evict spans waiting longer than the ttl", func(t *testing.T) {
+		tracker := spans.NewTracker(func(span spans.Span) {}, "gopulse.span").WithPendingTTL(10 * time.Millisecond)
+		handlers := tracker.Handlers()
+
+		// the start of the span was missed
+		handlers[1].Handler("gopulse.span.end", map[string]interface{}{telemetry.SpanIDKey: "orphan"}, nil, nil)
+		time.Sleep(20 * time.Millisecond)
+
+		// the next span triggers the eviction
+		handlers[0].Handler("gopulse.span.start", map[string]interface{}{telemetry.SpanIDKey: "next"}, nil, nil)
+
+		if tracker.Pending() != 1 || tracker.Evicted() != 1 {
+			t.Errorf("expected the orphan to be evicted, got %d pending and %d evicted", tracker.Pending(), tracker.Evicted())
+		}
+	})
+
+	t.Run("should evict the oldest spans over the cap", func(t *testing.T) {
+		var finished []string
+		tracker := spans.NewTracker(func(span spans.Span) {
+			finished = append(finished, span.SpanID)
+		}, "gopulse.span").WithMaxPending(2)
+		handlers := tracker.Handlers()
+
+		for _, spanID := range []string{"first", "second", "third"} {
+			handlers[0].Handler("gopulse.span.start", map[string]interface{}{telemetry.SpanIDKey: spanID}, nil, nil)
+		}
+
+		if tracker.Pending() != 2 || tracker.Evicted() != 1 {
+			t.Errorf("expected the oldest span to be evicted, got %d pending and %d evicted", tracker.Pending(), tracker.Evicted())
+		}
+
+		// the evicted span is not finished when its end arrives late
+		handlers[1].Handler("gopulse.span.end", map[string]interface{}{telemetry.SpanIDKey: "first"}, nil, nil)
+		handlers[1].Handler("gopulse.span.end", map[string]interface{}{telemetry.SpanIDKey: "third"}, nil, nil)
+
+		if len(finished) != 1 || finished[0] != "third" {
+			t.Errorf("expected only the third span to finish, got %v", finished)
+		}
+	})
+
+	t.Run("should not evict finished spans", func(t *testing.T) {
+		tracker := spans.NewTracker(func(span spans.Span) {}, "gopulse.span").WithMaxPending(2)
+		handlers := tracker.Handlers()
+
+		for i := 0; i < 1000; i++ {
+			spanID := fmt.Sprintf("span-%d", i)
+			handlers[0].Handler("gopulse.span.start", map[string]interface{}{telemetry.SpanIDKey: spanID}, nil, nil)
+			handlers[1].Handler("gopulse.span.end", map[string]interface{}{telemetry.SpanIDKey: spanID}, nil, nil)
+		}
+
+		if tracker.Pending() != 0 || tracker.Evicted() != 0 {
+			t.Errorf("expected no evictions, got %d pending and %d evicted", tracker.Pending(), tracker.Evicted())
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package sqlspan
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// a connection that triggers a span for every statement and transaction
+type conn struct {
+	driver *Driver
+	base   driver.Conn
+}
+
+/*
+wraps the base connection.
+database/sql checks the arguments against the connection, skipping the
+checkers of the statements, when a connection runs statements without
+preparing them. so the wrapper only does when the base connection does.
+*/
+func newConn(d *Driver, base driver.Conn) driver.Conn {
+	wrapped := &conn{driver: d, base: base}
+
+	_, execer := base.(driver.ExecerContext)
+	_, queryer := base.(driver.QueryerContext)
+	switch {
+	case execer && queryer:
+		return &execQueryConn{conn: wrapped}
+	case execer:
+		return &execConn{conn: wrapped}
+	case queryer:
+		return &queryConn{conn: wrapped}
+	default:
+		return wrapped
+	}
+}
+
+// a connection whose base connection runs statements without preparing them
+type execConn struct {
+	*conn
+}
+
+func (c *execConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.execContext(ctx, query, args)
+}
+
+// a connection whose base connection runs queries without preparing them
+type queryConn struct {
+	*conn
+}
+
+func (c *queryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.queryContext(ctx, query, args)
+}
+
+// a connection whose base connection runs statements and queries without preparing them
+type execQueryConn struct {
+	*conn
+}
+
+func (c *execQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.execContext(ctx, query, args)
+}
+
+func (c *execQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.queryContext(ctx, query, args)
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	result, err := c.driver.span("prepare", query, func() (any, error, map[string]interface{}) {
+		if preparer, ok := c.base.(driver.ConnPrepareContext); ok {
+			stmt, err := preparer.PrepareContext(ctx, query)
+			return stmt, err, nil
+		}
+
+		stmt, err := c.base.Prepare(query)
+		return stmt, err, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := &stmt{driver: c.driver, conn: c, base: result.(driver.Stmt), query: query}
+
+	// database/sql only uses a column converter when the statement has one
+	if _, ok := wrapped.base.(driver.ColumnConverter); ok {
+		return &converterStmt{stmt: wrapped}, nil
+	}
+
+	return wrapped, nil
+}
+
+func (c *conn) Close() error {
+	return c.base.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	result, err := c.driver.span("begin", "", func() (any, error, map[string]interface{}) {
+		if beginner, ok := c.base.(driver.ConnBeginTx); ok {
+			tx, err := beginner.BeginTx(ctx, opts)
+			return tx, err, nil
+		}
+
+		// the options can only be passed to drivers with BeginTx
+		if opts.Isolation != 0 || opts.ReadOnly {
+			return nil, ErrTxOptions, nil
+		}
+
+		tx, err := c.base.Begin()
+		return tx, err, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx{driver: c.driver, base: result.(driver.Tx)}, nil
+}
+
+// runs the statement without preparing it, the base connection must be an execer
+func (c *conn) execContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer := c.base.(driver.ExecerContext)
+
+	result, err := c.driver.fastPathSpan("exec", query, func() (any, error, map[string]interface{}) {
+		result, err := execer.ExecContext(ctx, query, args)
+		if err != nil {
+			return nil, err, nil
+		}
+
+		return result, nil, rowsAffected(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(driver.Result), nil
+}
+
+// runs the query without preparing it, the base connection must be a queryer
+func (c *conn) queryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer := c.base.(driver.QueryerContext)
+
+	result, err := c.driver.fastPathSpan("query", query, func() (any, error, map[string]interface{}) {
+		rows, err := queryer.QueryContext(ctx, query, args)
+		return rows, err, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(driver.Rows), nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.base.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.base.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if validator, ok := c.base.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+func (c *conn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.base.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
+
+// a prepared statement that triggers a span for every exec and query
+type stmt struct {
+	driver *Driver
+	conn   *conn
+	base   driver.Stmt
+	query  string
+}
+
+func (s *stmt) Close() error {
+	return s.base.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return s.base.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.base.Exec(args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.base.Query(args)
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	result, err := s.driver.span("exec", s.query, func() (any, error, map[string]interface{}) {
+		var result driver.Result
+		var err error
+
+		if execer, ok := s.base.(driver.StmtExecContext); ok {
+			result, err = execer.ExecContext(ctx, args)
+		} else {
+			var values []driver.Value
+			values, err = namedValues(args)
+			if err == nil {
+				result, err = s.base.Exec(values)
+			}
+		}
+		if err != nil {
+			return nil, err, nil
+		}
+
+		return result, nil, rowsAffected(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(driver.Result), nil
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := s.driver.span("query", s.query, func() (any, error, map[string]interface{}) {
+		if queryer, ok := s.base.(driver.StmtQueryContext); ok {
+			rows, err := queryer.QueryContext(ctx, args)
+			return rows, err, nil
+		}
+
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err, nil
+		}
+
+		rows, err := s.base.Query(values)
+		return rows, err, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(driver.Rows), nil
+}
+
+/*
+checks the value with the checker of the base statement, or of the base
+connection if the statement has none, as database/sql does for unwrapped drivers.
+*/
+func (s *stmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.base.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return s.conn.CheckNamedValue(value)
+}
+
+// a prepared statement whose base statement converts its arguments
+type converterStmt struct {
+	*stmt
+}
+
+func (s *converterStmt) ColumnConverter(index int) driver.ValueConverter {
+	return s.base.(driver.ColumnConverter).ColumnConverter(index)
+}
+
+// a transaction that triggers a span for its commit or rollback
+type tx struct {
+	driver *Driver
+	base   driver.Tx
+}
+
+func (t *tx) Commit() error {
+	_, err := t.driver.span("commit", "", func() (any, error, map[string]interface{}) {
+		return nil, t.base.Commit(), nil
+	})
+
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, err := t.driver.span("rollback", "", func() (any, error, map[string]interface{}) {
+		return nil, t.base.Rollback(), nil
+	})
+
+	return err
+}
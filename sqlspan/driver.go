@@ -0,0 +1,199 @@
+package sqlspan
+
+import (
+	"database/sql/driver"
+	"errors"
+	"runtime/debug"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// driver option func
+type DriverOption func(driver *Driver)
+
+/*
+A driver that wraps another driver and triggers a span for every
+connection, statement and transaction.
+the spans are {event}.connect, {event}.prepare, {event}.exec, {event}.query,
+{event}.begin, {event}.commit and {event}.rollback. statements are sanitized
+before they are added to the metadata, and exec spans have the rows
+affected as a measurement.
+*/
+type Driver struct {
+	provider telemetry.TelemetryInterface
+	base     driver.Driver
+	event    string
+}
+
+/*
+Wraps the base driver.
+register the wrapped driver under a new name to use it with database/sql
+
+	sql.Register("postgres-gopulse", sqlspan.NewDriver(telemetry, &pq.Driver{}))
+	db, err := sql.Open("postgres-gopulse", dsn)
+*/
+func NewDriver(provider telemetry.TelemetryInterface, base driver.Driver, options ...DriverOption) *Driver {
+	wrapped := &Driver{
+		provider: provider,
+		base:     base,
+		event:    "db",
+	}
+
+	for _, option := range options {
+		option(wrapped)
+	}
+
+	return wrapped
+}
+
+// helper functions for setting driver options
+
+// sets the event the span names are appended to
+func WithEvent(event string) DriverOption {
+	return func(driver *Driver) {
+		driver.event = event
+	}
+}
+
+// opens a connection in a connect span
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	result, err := d.span("connect", "", func() (any, error, map[string]interface{}) {
+		conn, err := d.base.Open(name)
+		return conn, err, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newConn(d, result.(driver.Conn)), nil
+}
+
+// private methods
+
+/*
+runs the func in the {event}.{operation} span.
+the end metadata has the operation, the sanitized statement and the error
+if the func failed, the measurement returned by the func is the end measurement.
+*/
+func (d *Driver) span(operation string, statement string, fn func() (any, error, map[string]interface{})) (any, error) {
+	metadata := spanMetadata(operation, statement)
+
+	return d.provider.TriggerSpan(d.event+"."+operation, metadata, func() (any, error, map[string]interface{}, map[string]interface{}) {
+		result, err, measurement := fn()
+		return result, err, measurement, endMetadata(metadata, err)
+	})
+}
+
+/*
+runs the func of a statement run without preparing it in the {event}.{operation} span.
+the base connection returns driver.ErrSkip when it can not run the statement, and
+database/sql then prepares it instead. the events are triggered by hand once the func
+returned, so no span is triggered for a skipped statement.
+*/
+func (d *Driver) fastPathSpan(operation string, statement string, fn func() (any, error, map[string]interface{})) (any, error) {
+	metadata := spanMetadata(operation, statement)
+	event := d.event + "." + operation
+	ids := map[string]interface{}{
+		telemetry.SpanIDKey:  telemetry.NewSpanID(),
+		telemetry.TraceIDKey: telemetry.NewTraceID(),
+	}
+	startTime := time.Now().UnixMilli()
+
+	defer func() {
+		if r := recover(); r != nil {
+			d.provider.TriggerEvent(event+telemetry.SpanStartSuffix, spanMeasurement(ids, map[string]interface{}{"start_time": startTime}), metadata)
+			d.provider.TriggerEvent(event+telemetry.SpanPanicSuffix, spanMeasurement(ids, map[string]interface{}{}), map[string]interface{}{
+				"error":      r,
+				"errorTime":  time.Now().UnixMilli(),
+				"stackTrace": string(debug.Stack()),
+			})
+
+			panic(r)
+		}
+	}()
+
+	result, err, measurement := fn()
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+
+	endTime := time.Now().UnixMilli()
+	d.provider.TriggerEvent(event+telemetry.SpanStartSuffix, spanMeasurement(ids, map[string]interface{}{"start_time": startTime}), metadata)
+
+	measurement = spanMeasurement(ids, measurement)
+	measurement["duration"] = endTime - startTime
+	measurement["end_time"] = endTime
+	d.provider.TriggerEvent(event+telemetry.SpanEndSuffix, measurement, endMetadata(metadata, err))
+
+	return result, err
+}
+
+// the operation and the sanitized statement
+func spanMetadata(operation string, statement string) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"operation": operation,
+	}
+	if statement != "" {
+		metadata["statement"] = Sanitize(statement)
+	}
+
+	return metadata
+}
+
+// a copy of the metadata with the error if the func failed
+func endMetadata(metadata map[string]interface{}, err error) map[string]interface{} {
+	ended := make(map[string]interface{}, len(metadata)+1)
+	for key, value := range metadata {
+		ended[key] = value
+	}
+	if err != nil {
+		ended["error"] = err.Error()
+	}
+
+	return ended
+}
+
+// returns a copy of the measurement with the span ids
+func spanMeasurement(ids map[string]interface{}, measurement map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(measurement)+len(ids)+2)
+	for key, value := range measurement {
+		copied[key] = value
+	}
+	for key, value := range ids {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// errors returned when the base driver does not support a feature
+var (
+	ErrNamedArgs = errors.New("sqlspan: the driver does not support named arguments")
+	ErrTxOptions = errors.New("sqlspan: the driver does not support isolation levels or read only transactions")
+)
+
+// converts named values to the positional values of the pre go 1.8 interfaces
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, ErrNamedArgs
+		}
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
+
+// the rows affected by an exec, -1 if the driver does not report it
+func rowsAffected(result driver.Result) map[string]interface{} {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		rows = -1
+	}
+
+	return map[string]interface{}{
+		"rows_affected": rows,
+	}
+}
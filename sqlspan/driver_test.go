@@ -0,0 +1,223 @@
+package sqlspan_test
+
+import (
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+	"github.com/trexreigns/gopulse/sqlspan"
+)
+
+func TestDriver(t *testing.T) {
+	t.Run("should trigger spans for statements", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "db.connect.end", "db.prepare.start", "db.prepare.end", "db.exec.start", "db.exec.end", "db.query.start", "db.query.end")
+
+		db, err := openFake(t, sqlspan.NewDriver(telemetry, &fakeDriver{}), "memory")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer db.Close()
+
+		if _, err := db.Exec("INSERT INTO users (name, age)\n  VALUES ('bob', 42)"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var count int64
+		if err := db.QueryRow("SELECT count(*) FROM users WHERE age > ?", 18).Scan(&count); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected 1 row, got %d", count)
+		}
+
+		if !mailer.AssertReceived("db.connect.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["operation"] == "connect"
+		}) {
+			t.Errorf("should assert received the connect span")
+		}
+
+		if !mailer.AssertReceived("db.exec.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["statement"] == "INSERT INTO users (name, age) VALUES (?, ?)" &&
+				box[0].Measurement["rows_affected"] == int64(1)
+		}) {
+			t.Errorf("should assert received the exec span")
+		}
+
+		if !mailer.AssertReceived("db.query.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["statement"] == "SELECT count(*) FROM users WHERE age > ?"
+		}) {
+			t.Errorf("should assert received the query span")
+		}
+
+		for _, span := range []string{"db.prepare", "db.exec", "db.query"} {
+			if !mailer.AssertSpanCompleted(span, 0) {
+				t.Errorf("should complete the %s span", span)
+			}
+		}
+	})
+
+	t.Run("should trigger spans for transactions", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "store.begin.end", "store.commit.start", "store.commit.end", "store.rollback.start", "store.rollback.end")
+
+		db, err := openFake(t, sqlspan.NewDriver(telemetry, &fakeDriver{}, sqlspan.WithEvent("store")), "memory")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer db.Close()
+
+		committed, _ := db.Begin()
+		committed.Commit()
+		rolledBack, _ := db.Begin()
+		rolledBack.Rollback()
+
+		if !mailer.AssertReceived("store.begin.end", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 2
+		}) {
+			t.Errorf("should assert received the begin spans")
+		}
+
+		for _, span := range []string{"store.commit", "store.rollback"} {
+			if !mailer.AssertSpanCompleted(span, 0) {
+				t.Errorf("should complete the %s span", span)
+			}
+		}
+	})
+
+	t.Run("should add the error to the end metadata", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "db.connect.end", "db.exec.end")
+
+		db, _ := openFake(t, sqlspan.NewDriver(telemetry, &fakeDriver{}), "memory")
+		defer db.Close()
+		if _, err := db.Exec("DELETE FROM users"); err == nil {
+			t.Errorf("expected the statement to fail")
+		}
+
+		unreachable, _ := openFake(t, sqlspan.NewDriver(telemetry, &fakeDriver{}), "unreachable")
+		defer unreachable.Close()
+		if err := unreachable.Ping(); err == nil {
+			t.Errorf("expected the connection to fail")
+		}
+
+		if !mailer.AssertReceived("db.exec.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Metadata["error"] == "fake: unsupported statement"
+		}) {
+			t.Errorf("should assert received the exec error")
+		}
+
+		if !mailer.AssertReceived("db.connect.end", func(event string, box ...mailbox.MailData) bool {
+			return box[len(box)-1].Metadata["error"] == "fake: connection refused"
+		}) {
+			t.Errorf("should assert received the connect error")
+		}
+	})
+}
+
+func TestDriverExecer(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, telemetry, "db.prepare.end", "db.exec.end")
+
+	db, _ := openFake(t, sqlspan.NewDriver(telemetry, &fakeDriver{execer: true}), "memory")
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO users (name) VALUES (?)", "bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !mailer.AssertReceived("db.exec.end", func(event string, box ...mailbox.MailData) bool {
+		return len(box) == 1 && box[0].Measurement["rows_affected"] == int64(1)
+	}) {
+		t.Errorf("should trigger one exec span")
+	}
+
+	if mailer.Stats("db.prepare.end").Total != 0 {
+		t.Errorf("should run the statement without preparing it")
+	}
+}
+
+func TestDriverSkip(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	mailer := mailbox.ForTest(t, telemetry, "db.prepare.end", "db.exec.start", "db.exec.end", "db.query.start", "db.query.end")
+
+	db, _ := openFake(t, sqlspan.NewDriver(telemetry, &fakeDriver{skip: true}), "memory")
+	defer db.Close()
+
+	if _, err := db.Exec("INSERT INTO users (name) VALUES (?)", "bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var count int64
+	if err := db.QueryRow("SELECT count(*) FROM users WHERE age > ?", 18).Scan(&count); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mailer.Stats("db.prepare.end").Total != 2 {
+		t.Errorf("should prepare the skipped statements")
+	}
+
+	// only the spans of the prepared statements, without the skip error
+	for _, event := range []string{"db.exec", "db.query"} {
+		if mailer.Stats(event+".start").Total != 1 || !mailer.AssertReceived(event+".end", func(event string, box ...mailbox.MailData) bool {
+			return len(box) == 1 && box[0].Metadata["error"] == nil
+		}) {
+			t.Errorf("should trigger a single %s span without an error", event)
+		}
+	}
+}
+
+func TestDriverArguments(t *testing.T) {
+	t.Run("should check arguments with the checker of the base connection", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		fake := &fakeDriver{checker: true}
+		db, _ := openFake(t, sqlspan.NewDriver(telemetry, fake), "memory")
+		defer db.Close()
+
+		if _, err := db.Exec("INSERT INTO payments (amount) VALUES (?)", cents{amount: 150}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fake.args) != 1 || fake.args[0] != int64(150) {
+			t.Errorf("expected the converted amount, got %v", fake.args)
+		}
+	})
+
+	t.Run("should convert arguments with the column converter of the base statement", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+
+		fake := &fakeDriver{converter: true}
+		db, _ := openFake(t, sqlspan.NewDriver(telemetry, fake), "memory")
+		defer db.Close()
+
+		if _, err := db.Exec("INSERT INTO payments (amount) VALUES (?)", cents{amount: 275}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fake.args) != 1 || fake.args[0] != int64(275) {
+			t.Errorf("expected the converted amount, got %v", fake.args)
+		}
+	})
+}
+
+func TestSanitize(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM users WHERE id = 42":                    "SELECT * FROM users WHERE id = ?",
+		"SELECT * FROM users WHERE name = 'O''Brien'":          "SELECT * FROM users WHERE name = ?",
+		"UPDATE users SET balance = 10.5 WHERE id = $1":        "UPDATE users SET balance = ? WHERE id = $1",
+		"SELECT user2, \"col3\" FROM t2\n\tWHERE  x IN (1, 2)": "SELECT user2, \"col3\" FROM t2 WHERE x IN (?, ?)",
+	}
+
+	for statement, expected := range cases {
+		if sanitized := sqlspan.Sanitize(statement); sanitized != expected {
+			t.Errorf("expected %q, got %q", expected, sanitized)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package sqlspan_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// a tiny in memory driver that counts the inserted rows
+type fakeDriver struct {
+	mu   sync.Mutex
+	rows int64
+	args []driver.Value // the arguments of the last exec
+
+	checker   bool // should the connections check cents arguments?
+	converter bool // should the statements convert cents arguments?
+	execer    bool // should the connections run statements without preparing them?
+	skip      bool // should the connections skip running statements without preparing them?
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	if name == "unreachable" {
+		return nil, errors.New("fake: connection refused")
+	}
+
+	conn := &fakeConn{driver: d}
+	if d.checker {
+		return &fakeCheckerConn{fakeConn: conn}, nil
+	}
+	if d.execer {
+		return &fakeExecerConn{fakeConn: conn}, nil
+	}
+	if d.skip {
+		return &fakeSkipConn{fakeConn: conn}, nil
+	}
+
+	return conn, nil
+}
+
+// an argument type the default converter of database/sql rejects
+type cents struct {
+	amount int64
+}
+
+func convertCents(value interface{}) (driver.Value, bool) {
+	money, ok := value.(cents)
+	return money.amount, ok
+}
+
+// a connection checking the arguments of its statements
+type fakeCheckerConn struct {
+	*fakeConn
+}
+
+func (c *fakeCheckerConn) CheckNamedValue(value *driver.NamedValue) error {
+	converted, ok := convertCents(value.Value)
+	if !ok {
+		return driver.ErrSkip
+	}
+	value.Value = converted
+
+	return nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	stmt := &fakeStmt{driver: c.driver, query: query}
+	if c.driver.converter {
+		return &fakeConverterStmt{fakeStmt: stmt}, nil
+	}
+
+	return stmt, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+type fakeStmt struct {
+	driver *fakeDriver
+	query  string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return strings.Count(s.query, "?")
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(s.query, "INSERT") {
+		return nil, errors.New("fake: unsupported statement")
+	}
+
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.rows++
+	s.driver.args = args
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	return &fakeRows{values: []int64{s.driver.rows}}, nil
+}
+
+// a connection running statements without preparing them
+type fakeExecerConn struct {
+	*fakeConn
+}
+
+func (c *fakeExecerConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return (&fakeStmt{driver: c.driver, query: query}).Exec(values)
+}
+
+// a connection asking database/sql to prepare every statement
+type fakeSkipConn struct {
+	*fakeConn
+}
+
+func (c *fakeSkipConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeSkipConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return nil, driver.ErrSkip
+}
+
+// a statement converting the arguments of its columns
+type fakeConverterStmt struct {
+	*fakeStmt
+}
+
+func (s *fakeConverterStmt) ColumnConverter(index int) driver.ValueConverter {
+	return centsConverter{}
+}
+
+type centsConverter struct{}
+
+func (c centsConverter) ConvertValue(value interface{}) (driver.Value, error) {
+	if converted, ok := convertCents(value); ok {
+		return converted, nil
+	}
+
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+type fakeRows struct {
+	values []int64
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"count"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+
+	return nil
+}
+
+type fakeTx struct{}
+
+func (t *fakeTx) Commit() error {
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	return nil
+}
+
+// numbers the registered drivers, as a name can only be registered once per process
+var registered atomic.Int64
+
+// registers the driver under a name unique to the test and the run, so tests can run with -count
+func openFake(t testing.TB, wrapped driver.Driver, dsn string) (*sql.DB, error) {
+	name := fmt.Sprintf("%s-%d", t.Name(), registered.Add(1))
+	sql.Register(name, wrapped)
+
+	return sql.Open(name, dsn)
+}
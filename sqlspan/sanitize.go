@@ -0,0 +1,71 @@
+package sqlspan
+
+import (
+	"strings"
+	"unicode"
+)
+
+/*
+Returns the statement with its literals replaced by ?.
+quoted strings and numbers are replaced, quoted identifiers are kept and
+runs of whitespace are collapsed to a single space, so statements that
+only differ by their values are the same.
+*/
+func Sanitize(statement string) string {
+	var builder strings.Builder
+	builder.Grow(len(statement))
+
+	runes := []rune(statement)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '\'':
+			// skip to the closing quote, a doubled quote is an escaped quote
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			writeToken(&builder, "?", &space)
+		case unicode.IsDigit(r) && !isIdentifier(runes, i):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			writeToken(&builder, "?", &space)
+		default:
+			writeToken(&builder, string(r), &space)
+		}
+	}
+
+	return builder.String()
+}
+
+// private methods
+
+// writes the token with a single space before it if whitespace was skipped
+func writeToken(builder *strings.Builder, token string, space *bool) {
+	if *space && builder.Len() > 0 {
+		builder.WriteByte(' ')
+	}
+	*space = false
+	builder.WriteString(token)
+}
+
+// is the digit part of an identifier or placeholder, such as user2 or $1
+func isIdentifier(runes []rune, i int) bool {
+	if i == 0 {
+		return false
+	}
+
+	previous := runes[i-1]
+	return unicode.IsLetter(previous) || unicode.IsDigit(previous) || previous == '_' || previous == '$' || previous == '"' || previous == '`'
+}
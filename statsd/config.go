@@ -0,0 +1,77 @@
+package statsd
+
+import "time"
+
+// config update func
+type ConfigUpdateFunc func(config *Config)
+
+// configs that are passed to the statsd reporter
+type Config struct {
+	Address       string        // the address of the statsd agent
+	Prefix        string        // prefix added to every metric name
+	DogStatsD     bool          // should the tags be sent in the dogstatsd format?
+	MaxPacketSize int           // the largest packet sent, lines are batched up to this size
+	FlushInterval time.Duration // how often the batched lines are sent
+}
+
+/*
+Registers a new statsd config.
+if no configs are provided, the default sets
+address to 127.0.0.1:8125,
+prefix to empty,
+dogStatsD to false,
+maxPacketSize to 1432 (an ethernet mtu minus the ip and udp headers),
+flushInterval to 1s
+*/
+func NewConfig(configs ...ConfigUpdateFunc) *Config {
+	statsdConfig := &Config{
+		Address:       "127.0.0.1:8125",
+		Prefix:        "",
+		DogStatsD:     false,
+		MaxPacketSize: 1432,
+		FlushInterval: time.Second,
+	}
+
+	for _, config := range configs {
+		config(statsdConfig)
+	}
+
+	return statsdConfig
+}
+
+// helper functions for setting statsd config
+
+// sets the address of the statsd agent
+func WithAddress(address string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Address = address
+	}
+}
+
+// sets the prefix added to every metric name
+func WithPrefix(prefix string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Prefix = prefix
+	}
+}
+
+// sets the dogstatsd flag
+func WithDogStatsD(dogStatsD bool) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.DogStatsD = dogStatsD
+	}
+}
+
+// sets the largest packet sent
+func WithMaxPacketSize(maxPacketSize int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxPacketSize = maxPacketSize
+	}
+}
+
+// sets how often the batched lines are sent
+func WithFlushInterval(flushInterval time.Duration) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.FlushInterval = flushInterval
+	}
+}
@@ -0,0 +1,263 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+)
+
+/*
+A reporter that sends events to a statsd agent over udp.
+counters and sums are sent as counters, last values as gauges,
+summaries and distributions as timers. lines are batched into packets of
+at most MaxPacketSize bytes and sent when a packet is full or on every
+flush interval.
+*/
+type Reporter struct {
+	id      string
+	config  *Config
+	metrics []metrics.Metric
+	conn    net.Conn
+
+	mu      sync.Mutex
+	packet  []byte
+	lines   int           // lines batched in the packet
+	dropped atomic.Uint64 // lines that failed to send
+
+	done     chan struct{}
+	stopped  sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// errors returned by NewReporter when the config is invalid
+var (
+	ErrInvalidFlushInterval = errors.New("statsd: the flush interval must be positive")
+	ErrInvalidMaxPacketSize = errors.New("statsd: the max packet size must be positive")
+)
+
+// reporter will implement the telemetry handler interface
+
+func NewReporter(id string, config *Config, definitions ...metrics.Metric) (*Reporter, error) {
+	if config.FlushInterval <= 0 {
+		return nil, ErrInvalidFlushInterval
+	}
+	if config.MaxPacketSize <= 0 {
+		return nil, ErrInvalidMaxPacketSize
+	}
+
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter := &Reporter{
+		id:      id,
+		config:  config,
+		metrics: definitions,
+		conn:    conn,
+		packet:  make([]byte, 0, config.MaxPacketSize),
+		done:    make(chan struct{}),
+	}
+
+	reporter.stopped.Add(1)
+	go reporter.flushLoop()
+
+	return reporter, nil
+}
+
+func (r *Reporter) ID() string {
+	return r.id
+}
+
+func (r *Reporter) Config() interface{} {
+	return r.config
+}
+
+func (r *Reporter) AttachedHandlers() []telemetry.EventRegistrar {
+	return metrics.Registrars(r.handleEvent, r.metrics...)
+}
+
+// sends the batched lines
+func (r *Reporter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.send()
+}
+
+// returns the number of lines that failed to send
+func (r *Reporter) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// stops the flush loop, sends the batched lines and closes the connection
+func (r *Reporter) Stop() error {
+	var err error
+	r.stopOnce.Do(func() {
+		close(r.done)
+		r.stopped.Wait()
+
+		r.Flush()
+		err = r.conn.Close()
+	})
+
+	return err
+}
+
+// private methods
+
+func (r *Reporter) flushLoop() {
+	defer r.stopped.Done()
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.Flush()
+		}
+	}
+}
+
+func (r *Reporter) handleEvent(event string, measurement map[string]interface{}, metadata map[string]interface{}, config interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, metric := range r.metrics {
+		if metric.Event != event {
+			continue
+		}
+
+		lines, count := r.formatLines(metric, measurement, metadata)
+		if count == 0 {
+			continue
+		}
+
+		r.enqueue(lines, count)
+	}
+}
+
+// add the lines to the packet, sending the packet first if the lines do not fit
+func (r *Reporter) enqueue(lines []byte, count int) {
+	size := len(r.packet) + len(lines)
+	if r.lines > 0 {
+		size++ // newline separating the lines
+	}
+
+	if r.lines > 0 && size > r.config.MaxPacketSize {
+		r.send()
+	}
+
+	if r.lines > 0 {
+		r.packet = append(r.packet, '\n')
+	}
+	r.packet = append(r.packet, lines...)
+	r.lines += count
+}
+
+// send the packet, the caller must hold the lock
+func (r *Reporter) send() {
+	if r.lines == 0 {
+		return
+	}
+
+	if _, err := r.conn.Write(r.packet); err != nil {
+		r.dropped.Add(uint64(r.lines))
+	}
+
+	r.packet = r.packet[:0]
+	r.lines = 0
+}
+
+/*
+format the statsd lines of the metric, returns no lines if the event has no value for it.
+a gauge with a sign is changed by the value instead of set to it, so a negative
+gauge is set to zero first.
+*/
+func (r *Reporter) formatLines(metric metrics.Metric, measurement map[string]interface{}, metadata map[string]interface{}) ([]byte, int) {
+	value := 1.0
+	if metric.Measurement != "" {
+		number, ok := metrics.ToFloat(measurement[metric.Measurement])
+		if !ok {
+			return nil, 0
+		}
+
+		// counters count the events carrying the measurement
+		if metric.Kind != metrics.KindCounter {
+			value = number
+		}
+	}
+
+	if metric.Kind == metrics.KindLastValue && value < 0 {
+		lines := r.appendLine(make([]byte, 0, 128), metric, 0, metadata)
+		lines = append(lines, '\n')
+		return r.appendLine(lines, metric, value, metadata), 2
+	}
+
+	return r.appendLine(make([]byte, 0, 64), metric, value, metadata), 1
+}
+
+// append the statsd line of the metric with the value
+func (r *Reporter) appendLine(line []byte, metric metrics.Metric, value float64, metadata map[string]interface{}) []byte {
+	if r.config.Prefix != "" {
+		line = append(line, sanitize(r.config.Prefix)...)
+		line = append(line, '.')
+	}
+	line = append(line, sanitize(metric.Name)...)
+	line = append(line, ':')
+	line = strconv.AppendFloat(line, value, 'f', -1, 64)
+	line = append(line, '|')
+	line = append(line, metricType(metric.Kind)...)
+
+	if r.config.DogStatsD && len(metric.Tags) > 0 {
+		line = append(line, "|#"...)
+		for i, tag := range metric.Tags {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, sanitize(tag)...)
+			line = append(line, ':')
+			line = append(line, sanitize(tagValue(metadata[tag]))...)
+		}
+	}
+
+	return line
+}
+
+// returns the statsd type of the metric kind
+func metricType(kind metrics.Kind) string {
+	switch kind {
+	case metrics.KindLastValue:
+		return "g"
+	case metrics.KindSummary, metrics.KindDistribution:
+		return "ms"
+	default:
+		return "c"
+	}
+}
+
+func tagValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// characters that delimit the parts of a statsd line
+var sanitizer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_", " ", "_")
+
+func sanitize(value string) string {
+	return sanitizer.Replace(value)
+}
@@ -0,0 +1,227 @@
+package statsd_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/metrics"
+	"github.com/trexreigns/gopulse/providers"
+	"github.com/trexreigns/gopulse/statsd"
+)
+
+// start a local udp listener standing in for the statsd agent
+func listen(t *testing.T) net.PacketConn {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	return listener
+}
+
+// read the packets received before the timeout
+func readPackets(t *testing.T, listener net.PacketConn, timeout time.Duration) []string {
+	packets := make([]string, 0)
+	buffer := make([]byte, 65536)
+
+	listener.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		n, _, err := listener.ReadFrom(buffer)
+		if err != nil {
+			return packets
+		}
+		packets = append(packets, string(buffer[:n]))
+	}
+}
+
+func newReporter(t *testing.T, listener net.PacketConn, configs []statsd.ConfigUpdateFunc, definitions ...metrics.Metric) *statsd.Reporter {
+	configs = append([]statsd.ConfigUpdateFunc{statsd.WithAddress(listener.LocalAddr().String())}, configs...)
+
+	reporter, err := statsd.NewReporter("statsd", statsd.NewConfig(configs...), definitions...)
+	if err != nil {
+		t.Fatalf("failed to create the reporter: %v", err)
+	}
+	t.Cleanup(func() {
+		reporter.Stop()
+	})
+
+	return reporter
+}
+
+func TestReporterLines(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	listener := listen(t)
+
+	reporter := newReporter(t, listener, []statsd.ConfigUpdateFunc{statsd.WithPrefix("app"), statsd.WithDogStatsD(true), statsd.WithFlushInterval(time.Hour)},
+		metrics.Counter("http.server.request.end", "", metrics.WithName("http.requests"), metrics.WithTags("method", "route")),
+		metrics.Distribution("http.server.request.end", "duration", metrics.WithName("http.duration")),
+		metrics.LastValue("vm.goroutines", "count"),
+	)
+	telemetry.AddHandlers(reporter)
+
+	telemetry.TriggerEvent("http.server.request.end", map[string]interface{}{
+		"duration": int64(42),
+	}, map[string]interface{}{
+		"method": "GET",
+		"route":  "/users/:id",
+	})
+	telemetry.TriggerEvent("vm.goroutines", map[string]interface{}{"count": 12}, map[string]interface{}{})
+	reporter.Flush()
+
+	packets := readPackets(t, listener, 200*time.Millisecond)
+	if len(packets) != 1 {
+		t.Fatalf("expected the lines in a single packet, got %q", packets)
+	}
+
+	expected := strings.Join([]string{
+		"app.http.requests:1|c|#method:GET,route:/users/_id",
+		"app.http.duration:42|ms",
+		"app.vm.goroutines.count:12|g",
+	}, "\n")
+	if packets[0] != expected {
+		t.Errorf("unexpected packet\n got: %q\nwant: %q", packets[0], expected)
+	}
+}
+
+func TestReporterNegativeGauges(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	listener := listen(t)
+
+	reporter := newReporter(t, listener, []statsd.ConfigUpdateFunc{statsd.WithFlushInterval(time.Hour)},
+		metrics.LastValue("account.balance", "amount"),
+	)
+	telemetry.AddHandlers(reporter)
+
+	telemetry.TriggerEvent("account.balance", map[string]interface{}{"amount": -5}, map[string]interface{}{})
+	telemetry.TriggerEvent("account.balance", map[string]interface{}{"amount": 3}, map[string]interface{}{})
+	reporter.Flush()
+
+	// a signed gauge is a decrement, so the gauge is set to zero before the negative value
+	packets := readPackets(t, listener, 200*time.Millisecond)
+	expected := "account.balance.amount:0|g\naccount.balance.amount:-5|g\naccount.balance.amount:3|g"
+	if len(packets) != 1 || packets[0] != expected {
+		t.Errorf("unexpected packets %q", packets)
+	}
+}
+
+func TestReporterOmitsTagsWithoutDogStatsD(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	listener := listen(t)
+
+	reporter := newReporter(t, listener, []statsd.ConfigUpdateFunc{statsd.WithFlushInterval(time.Hour)},
+		metrics.Sum("queue.push", "size", metrics.WithTags("queue")),
+	)
+	telemetry.AddHandlers(reporter)
+
+	telemetry.TriggerEvent("queue.push", map[string]interface{}{"size": 3}, map[string]interface{}{"queue": "emails"})
+	reporter.Flush()
+
+	packets := readPackets(t, listener, 200*time.Millisecond)
+	if len(packets) != 1 || packets[0] != "queue.push.size:3|c" {
+		t.Errorf("unexpected packets %q", packets)
+	}
+}
+
+func TestReporterBatchesPackets(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	listener := listen(t)
+
+	maxPacketSize := 64
+	reporter := newReporter(t, listener, []statsd.ConfigUpdateFunc{statsd.WithMaxPacketSize(maxPacketSize), statsd.WithFlushInterval(time.Hour)},
+		metrics.Counter("jobs.done", ""),
+	)
+	telemetry.AddHandlers(reporter)
+
+	// each line is "jobs.done:1|c", 13 bytes
+	for i := 0; i < 20; i++ {
+		telemetry.TriggerEvent("jobs.done", map[string]interface{}{}, map[string]interface{}{})
+	}
+	reporter.Flush()
+
+	packets := readPackets(t, listener, 200*time.Millisecond)
+	if len(packets) < 2 {
+		t.Fatalf("expected the lines to be split over several packets, got %d", len(packets))
+	}
+
+	lines := 0
+	for _, packet := range packets {
+		if len(packet) > maxPacketSize {
+			t.Errorf("packet of %d bytes exceeds the max packet size", len(packet))
+		}
+		lines += len(strings.Split(packet, "\n"))
+	}
+
+	if lines != 20 {
+		t.Errorf("expected 20 lines, got %d", lines)
+	}
+}
+
+func TestReporterFlushInterval(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	listener := listen(t)
+
+	reporter := newReporter(t, listener, []statsd.ConfigUpdateFunc{statsd.WithFlushInterval(20 * time.Millisecond)},
+		metrics.Counter("jobs.done", ""),
+	)
+	telemetry.AddHandlers(reporter)
+
+	telemetry.TriggerEvent("jobs.done", map[string]interface{}{}, map[string]interface{}{})
+
+	packets := readPackets(t, listener, 200*time.Millisecond)
+	if len(packets) != 1 || packets[0] != "jobs.done:1|c" {
+		t.Errorf("expected the line to be flushed on the interval, got %q", packets)
+	}
+}
+
+func TestReporterRejectsInvalidConfig(t *testing.T) {
+	listener := listen(t)
+	address := statsd.WithAddress(listener.LocalAddr().String())
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := statsd.NewReporter("statsd", statsd.NewConfig(address, statsd.WithFlushInterval(interval)))
+		if !errors.Is(err, statsd.ErrInvalidFlushInterval) {
+			t.Errorf("expected an invalid flush interval error for %v, got %v", interval, err)
+		}
+	}
+
+	for _, size := range []int{0, -1} {
+		_, err := statsd.NewReporter("statsd", statsd.NewConfig(address, statsd.WithMaxPacketSize(size)))
+		if !errors.Is(err, statsd.ErrInvalidMaxPacketSize) {
+			t.Errorf("expected an invalid max packet size error for %d, got %v", size, err)
+		}
+	}
+}
+
+func TestReporterCountsDroppedLines(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	listener := listen(t)
+
+	reporter := newReporter(t, listener, []statsd.ConfigUpdateFunc{statsd.WithFlushInterval(time.Hour)},
+		metrics.Counter("jobs.done", ""),
+	)
+	telemetry.AddHandlers(reporter)
+
+	// the connection is closed so every send fails
+	reporter.Stop()
+
+	telemetry.TriggerEvent("jobs.done", map[string]interface{}{}, map[string]interface{}{})
+	telemetry.TriggerEvent("jobs.done", map[string]interface{}{}, map[string]interface{}{})
+	reporter.Flush()
+
+	if dropped := reporter.Dropped(); dropped != 2 {
+		t.Errorf("expected 2 dropped lines, got %d", dropped)
+	}
+}
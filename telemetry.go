@@ -1,10 +1,55 @@
 package telemetry
 
+import (
+	"context"
+	"time"
+)
+
 // telemetry event definition
 
 // span execution func
 type SpanFunc[T any] func() (T, error, map[string]interface{}, map[string]interface{})
 
+/*
+Implemented by providers that can wait for their handlers to settle.
+it is kept out of TelemetryInterface so other implementations do not have to
+provide it, check for it with a type assertion
+
+	if idler, ok := provider.(telemetry.Idler); ok {
+		idler.WaitIdle(ctx, 10*time.Millisecond)
+	}
+*/
+type Idler interface {
+	// wait until no handler is running and no event has been triggered for the quiet window
+	WaitIdle(ctx context.Context, quiet time.Duration) error
+}
+
+/*
+Implemented by providers that can return scoped views of themselves.
+like Idler, it is kept out of TelemetryInterface and is checked for with a type assertion.
+*/
+type Scoper interface {
+	// returns a view that prefixes event names and adds default metadata
+	With(prefix string, metadata map[string]interface{}) TelemetryInterface
+}
+
+// a background service started and stopped with a provider, such as a poller
+type Service interface {
+	Start()
+	Stop()
+}
+
+/*
+Implemented by providers that start and stop services with themselves.
+like Idler, it is kept out of TelemetryInterface and is checked for with a type assertion.
+*/
+type Lifecycle interface {
+	// starts the services, they are stopped when the provider is stopped
+	StartServices(services ...Service)
+	// stops the services in reverse order, waits for the handlers to settle and stops the provider
+	Stop(ctx context.Context) error
+}
+
 // Telemetry interface
 type TelemetryInterface interface {
 	// add a new handler to the telemetry
@@ -8,6 +8,8 @@ type TelemetryConfig struct {
 	AllowConcurrentExecution bool // should the telemetry requests run concurrently?
 	ConcurrentPoolSize       int  // the size of the concurrent pool if running concurrently
 	ConcurrentBufferSize     int  // the size of the concurrent buffer if running concurrently
+
+	Processors []ProcessorFunc // run in order on every event before it is dispatched to the handlers
 }
 
 /*
@@ -15,13 +17,15 @@ Registers a new telemetry config.
 if no configs are provided, the default sets
 allowConcurrentExecution to false,
 concurrentPoolSize to 0,
-concurrentBufferSize to 0
+concurrentBufferSize to 0,
+processors to none
 */
 func NewTelemetryConfig(configs ...TelemetryConfigUpdateFunc) *TelemetryConfig {
 	telemetryConfig := &TelemetryConfig{
 		AllowConcurrentExecution: false,
 		ConcurrentPoolSize:       0,
 		ConcurrentBufferSize:     0,
+		Processors:               []ProcessorFunc{},
 	}
 
 	for _, config := range configs {
@@ -53,3 +57,10 @@ func WithConcurrentBufferSize(concurrentBufferSize int) TelemetryConfigUpdateFun
 		config.ConcurrentBufferSize = concurrentBufferSize
 	}
 }
+
+// adds processors run on every event before it is dispatched
+func WithProcessors(processors ...ProcessorFunc) TelemetryConfigUpdateFunc {
+	return func(config *TelemetryConfig) {
+		config.Processors = append(config.Processors, processors...)
+	}
+}
@@ -0,0 +1,173 @@
+package traceparent
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	telemetry "github.com/trexreigns/gopulse"
+)
+
+// the w3c trace context headers
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// the only traceparent version defined by the w3c trace context spec
+const version = "00"
+
+// the sampled bit of the trace flags
+const sampledFlag = 0x01
+
+// error returned when a traceparent header can not be parsed
+var ErrInvalidTraceparent = errors.New("traceparent: invalid traceparent")
+
+// the trace context carried across process boundaries
+type SpanContext struct {
+	TraceID    string // 16 byte trace id encoded as hex
+	SpanID     string // 8 byte span id encoded as hex
+	Flags      byte   // trace flags, the lowest bit is the sampled flag
+	TraceState string // vendor specific trace state, passed on unchanged
+}
+
+/*
+Parses a traceparent header such as
+00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+headers of future versions are parsed by their first four fields, as the spec requires.
+*/
+func Parse(traceparent string) (SpanContext, error) {
+	fields := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(fields) < 4 || (fields[0] == version && len(fields) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	headerVersion, traceID, spanID, flags := fields[0], fields[1], fields[2], fields[3]
+	if !isHex(headerVersion, 1) || headerVersion == "ff" || !isHex(traceID, 16) || !isHex(spanID, 8) || !isHex(flags, 1) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	decodedFlags, _ := hex.DecodeString(flags)
+	spanContext := SpanContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Flags:   decodedFlags[0],
+	}
+	if !spanContext.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	return spanContext, nil
+}
+
+// are the trace and span ids set and not all zeros?
+func (s SpanContext) IsValid() bool {
+	return isHex(s.TraceID, 16) && isHex(s.SpanID, 8) &&
+		strings.Trim(s.TraceID, "0") != "" && strings.Trim(s.SpanID, "0") != ""
+}
+
+// is the sampled flag set?
+func (s SpanContext) Sampled() bool {
+	return s.Flags&sampledFlag != 0
+}
+
+// returns the traceparent header of the span context
+func (s SpanContext) Traceparent() string {
+	return fmt.Sprintf("%s-%s-%s-%02x", version, s.TraceID, s.SpanID, s.Flags)
+}
+
+type contextKey struct{}
+
+// returns a copy of the context carrying the span context
+func NewContext(ctx context.Context, spanContext SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, spanContext)
+}
+
+// returns the span context carried by the context
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	spanContext, ok := ctx.Value(contextKey{}).(SpanContext)
+	return spanContext, ok
+}
+
+/*
+Returns a copy of the context carrying the span context of the headers.
+the context is returned unchanged if the traceparent header is missing or invalid.
+*/
+func Extract(ctx context.Context, header http.Header) context.Context {
+	spanContext, err := Parse(header.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+	spanContext.TraceState = strings.Join(header.Values(TracestateHeader), ",")
+
+	return NewContext(ctx, spanContext)
+}
+
+// sets the traceparent and tracestate headers to the span context carried by the context
+func Inject(ctx context.Context, header http.Header) {
+	spanContext, ok := FromContext(ctx)
+	if !ok || !spanContext.IsValid() {
+		return
+	}
+
+	header.Set(TraceparentHeader, spanContext.Traceparent())
+	if spanContext.TraceState != "" {
+		header.Set(TracestateHeader, spanContext.TraceState)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
+
+/*
+Starts a child of the span context carried by the context.
+returns a copy of the context carrying the child and a copy of the metadata
+seeded with its ids, so the span triggered with the metadata is the child
+
+	ctx, metadata = traceparent.Seed(ctx, metadata)
+	provider.TriggerSpan("checkout", metadata, spanFunc)
+
+the child starts a new sampled trace if the context carries no span context.
+*/
+func Seed(ctx context.Context, metadata map[string]interface{}) (context.Context, map[string]interface{}) {
+	child := SpanContext{
+		TraceID: telemetry.NewTraceID(),
+		SpanID:  telemetry.NewSpanID(),
+		Flags:   sampledFlag,
+	}
+
+	seeded := make(map[string]interface{}, len(metadata)+3)
+	for key, value := range metadata {
+		seeded[key] = value
+	}
+
+	if parent, ok := FromContext(ctx); ok && parent.IsValid() {
+		child.TraceID = parent.TraceID
+		child.Flags = parent.Flags
+		child.TraceState = parent.TraceState
+		seeded[telemetry.ParentSpanIDKey] = parent.SpanID
+	}
+	seeded[telemetry.TraceIDKey] = child.TraceID
+	seeded[telemetry.SpanIDKey] = child.SpanID
+
+	return NewContext(ctx, child), seeded
+}
+
+// private methods
+
+// is the value lowercase hex of the size in bytes?
+func isHex(value string, size int) bool {
+	if len(value) != size*2 {
+		return false
+	}
+
+	for _, r := range value {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
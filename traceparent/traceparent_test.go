@@ -0,0 +1,143 @@
+package traceparent_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/mailbox"
+	"github.com/trexreigns/gopulse/providers"
+	"github.com/trexreigns/gopulse/traceparent"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+	header  = "00-" + traceID + "-" + spanID + "-01"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should parse a traceparent", func(t *testing.T) {
+		spanContext, err := traceparent.Parse(header)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if spanContext.TraceID != traceID || spanContext.SpanID != spanID || !spanContext.Sampled() {
+			t.Errorf("unexpected span context %+v", spanContext)
+		}
+
+		if spanContext.Traceparent() != header {
+			t.Errorf("expected %q, got %q", header, spanContext.Traceparent())
+		}
+	})
+
+	t.Run("should parse future versions by their first fields", func(t *testing.T) {
+		spanContext, err := traceparent.Parse("cc-" + traceID + "-" + spanID + "-00-future")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if spanContext.Sampled() {
+			t.Errorf("should not be sampled")
+		}
+	})
+
+	t.Run("should reject invalid traceparents", func(t *testing.T) {
+		invalid := []string{
+			"",
+			"00-" + traceID + "-" + spanID,
+			"00-" + traceID + "-" + spanID + "-01-extra",
+			"ff-" + traceID + "-" + spanID + "-01",
+			"00-00000000000000000000000000000000-" + spanID + "-01",
+			"00-" + traceID + "-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01",
+			"00-" + traceID + "-" + spanID + "-1",
+		}
+
+		for _, value := range invalid {
+			if _, err := traceparent.Parse(value); err != traceparent.ErrInvalidTraceparent {
+				t.Errorf("expected %q to be invalid, got %v", value, err)
+			}
+		}
+	})
+}
+
+func TestPropagation(t *testing.T) {
+	t.Run("should extract and inject the headers", func(t *testing.T) {
+		incoming := http.Header{}
+		incoming.Set(traceparent.TraceparentHeader, header)
+		incoming.Set(traceparent.TracestateHeader, "vendor=value")
+
+		ctx := traceparent.Extract(context.Background(), incoming)
+
+		outgoing := http.Header{}
+		traceparent.Inject(ctx, outgoing)
+
+		if outgoing.Get(traceparent.TraceparentHeader) != header {
+			t.Errorf("expected %q, got %q", header, outgoing.Get(traceparent.TraceparentHeader))
+		}
+		if outgoing.Get(traceparent.TracestateHeader) != "vendor=value" {
+			t.Errorf("should pass on the tracestate, got %q", outgoing.Get(traceparent.TracestateHeader))
+		}
+	})
+
+	t.Run("should ignore an invalid traceparent", func(t *testing.T) {
+		incoming := http.Header{}
+		incoming.Set(traceparent.TraceparentHeader, "invalid")
+
+		ctx := traceparent.Extract(context.Background(), incoming)
+		if _, ok := traceparent.FromContext(ctx); ok {
+			t.Errorf("should not carry a span context")
+		}
+	})
+
+	t.Run("should seed the trace of a span", func(t *testing.T) {
+		// register telemetry
+		telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+		mailer := mailbox.ForTest(t, telemetry, "checkout.start", "checkout.end")
+
+		parent, _ := traceparent.Parse(header)
+		ctx := traceparent.NewContext(context.Background(), parent)
+
+		ctx, metadata := traceparent.Seed(ctx, map[string]interface{}{"cart": "c1"})
+		telemetry.TriggerSpan("checkout", metadata, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			return nil, nil, nil, nil
+		})
+
+		child, _ := traceparent.FromContext(ctx)
+		if child.TraceID != traceID || child.SpanID == spanID {
+			t.Errorf("expected a child of the parent, got %+v", child)
+		}
+
+		if !mailer.AssertReceived("checkout.start", func(event string, box ...mailbox.MailData) bool {
+			_, seeded := box[0].Metadata["trace_id"]
+			return box[0].Measurement["trace_id"] == traceID &&
+				box[0].Measurement["span_id"] == child.SpanID &&
+				box[0].Measurement["parent_span_id"] == spanID &&
+				box[0].Metadata["cart"] == "c1" && !seeded
+		}) {
+			t.Errorf("should start the span with the seeded ids")
+		}
+
+		if !mailer.AssertReceived("checkout.end", func(event string, box ...mailbox.MailData) bool {
+			return box[0].Measurement["trace_id"] == traceID && box[0].Measurement["span_id"] == child.SpanID
+		}) {
+			t.Errorf("should end the span with the seeded ids")
+		}
+	})
+
+	t.Run("should start a new trace without a parent", func(t *testing.T) {
+		ctx, metadata := traceparent.Seed(context.Background(), nil)
+
+		child, ok := traceparent.FromContext(ctx)
+		if !ok || !child.IsValid() || !child.Sampled() {
+			t.Errorf("expected a new sampled span context, got %+v", child)
+		}
+
+		if _, ok := metadata["parent_span_id"]; ok || metadata["trace_id"] != child.TraceID {
+			t.Errorf("unexpected seeded metadata %v", metadata)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package zipkin
+
+import (
+	"net/http"
+	"time"
+)
+
+// config update func
+type ConfigUpdateFunc func(config *Config)
+
+// configs that are passed to the zipkin exporter
+type Config struct {
+	Endpoint      string        // the url spans are posted to
+	ServiceName   string        // the service name of the local endpoint
+	Spans         []string      // base events of the spans exported
+	BatchSize     int           // the number of spans sent in a request
+	MaxQueueSize  int           // the number of finished spans held before new ones are dropped
+	FlushInterval time.Duration // how often the spans are exported
+	Client        *http.Client  // the client used to send requests
+}
+
+/*
+Registers a new zipkin config.
+if no configs are provided, the default sets
+endpoint to http://localhost:9411/api/v2/spans,
+serviceName to gopulse,
+batchSize to 100,
+maxQueueSize to 1000,
+flushInterval to 1s,
+client to a client with a 10s timeout
+*/
+func NewConfig(configs ...ConfigUpdateFunc) *Config {
+	zipkinConfig := &Config{
+		Endpoint:      "http://localhost:9411/api/v2/spans",
+		ServiceName:   "gopulse",
+		Spans:         []string{},
+		BatchSize:     100,
+		MaxQueueSize:  1000,
+		FlushInterval: time.Second,
+		Client:        &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, config := range configs {
+		config(zipkinConfig)
+	}
+
+	return zipkinConfig
+}
+
+// helper functions for setting zipkin config
+
+// sets the url spans are posted to
+func WithEndpoint(endpoint string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Endpoint = endpoint
+	}
+}
+
+// sets the service name of the local endpoint
+func WithServiceName(serviceName string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.ServiceName = serviceName
+	}
+}
+
+// adds the base events of spans exported
+func WithSpans(events ...string) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Spans = append(config.Spans, events...)
+	}
+}
+
+// sets the number of spans sent in a request
+func WithBatchSize(batchSize int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.BatchSize = batchSize
+	}
+}
+
+// sets the number of finished spans held before new ones are dropped
+func WithMaxQueueSize(maxQueueSize int) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.MaxQueueSize = maxQueueSize
+	}
+}
+
+// sets how often the spans are exported
+func WithFlushInterval(flushInterval time.Duration) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.FlushInterval = flushInterval
+	}
+}
+
+// sets the client used to send requests
+func WithClient(client *http.Client) ConfigUpdateFunc {
+	return func(config *Config) {
+		config.Client = client
+	}
+}
@@ -0,0 +1,184 @@
+package zipkin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/spans"
+)
+
+// a span in the zipkin v2 json format
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"` // microseconds since the epoch
+	Duration      int64             `json:"duration"`  // microseconds
+	LocalEndpoint endpoint          `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Annotations   []annotation      `json:"annotations,omitempty"`
+}
+
+type endpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type annotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+/*
+An exporter that posts spans to zipkin in the v2 json format.
+spans are paired from the start, end and panic events of TriggerSpan,
+their metadata becomes tags and panics are tagged as errors.
+*/
+type Exporter struct {
+	id      string
+	config  *Config
+	tracker *spans.Tracker
+	batcher *spans.Batcher
+}
+
+// errors returned by NewExporter when the config is invalid
+var (
+	ErrInvalidBatchSize     = errors.New("zipkin: the batch size must be positive")
+	ErrInvalidMaxQueueSize  = errors.New("zipkin: the max queue size must be positive")
+	ErrInvalidFlushInterval = errors.New("zipkin: the flush interval must be positive")
+)
+
+// exporter will implement the telemetry handler interface
+
+func NewExporter(id string, config *Config) (*Exporter, error) {
+	if config.BatchSize <= 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	if config.MaxQueueSize <= 0 {
+		return nil, ErrInvalidMaxQueueSize
+	}
+	if config.FlushInterval <= 0 {
+		return nil, ErrInvalidFlushInterval
+	}
+
+	exporter := &Exporter{
+		id:     id,
+		config: config,
+	}
+	exporter.batcher = spans.NewBatcher(exporter.post,
+		spans.WithBatchSize(config.BatchSize),
+		spans.WithMaxQueueSize(config.MaxQueueSize),
+		spans.WithFlushInterval(config.FlushInterval),
+	)
+	exporter.tracker = spans.NewTracker(exporter.batcher.Add, config.Spans...)
+
+	return exporter, nil
+}
+
+func (e *Exporter) ID() string {
+	return e.id
+}
+
+func (e *Exporter) Config() interface{} {
+	return e.config
+}
+
+func (e *Exporter) AttachedHandlers() []telemetry.EventRegistrar {
+	return e.tracker.Handlers()
+}
+
+// returns the number of spans dropped
+func (e *Exporter) Dropped() uint64 {
+	return e.batcher.Dropped()
+}
+
+// posts the queued spans
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.batcher.Flush(ctx)
+}
+
+// stops the export loop and posts everything still queued
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.batcher.Shutdown(ctx)
+}
+
+// private methods
+
+func (e *Exporter) post(ctx context.Context, batch []spans.Span) error {
+	encoded := make([]zipkinSpan, 0, len(batch))
+	for _, span := range batch {
+		encoded = append(encoded, e.encode(span))
+	}
+
+	body, err := json.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := e.config.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("zipkin: %s responded with %s", e.config.Endpoint, response.Status)
+	}
+
+	return nil
+}
+
+func (e *Exporter) encode(span spans.Span) zipkinSpan {
+	// zipkin rejects spans without a duration, spans are measured in milliseconds
+	duration := span.Duration().Microseconds()
+	if duration < 1 {
+		duration = 1
+	}
+
+	encoded := zipkinSpan{
+		TraceID:       span.TraceID,
+		ID:            span.SpanID,
+		ParentID:      span.ParentSpanID,
+		Name:          span.Name,
+		Timestamp:     span.Start.UnixMicro(),
+		Duration:      duration,
+		LocalEndpoint: endpoint{ServiceName: e.config.ServiceName},
+		Tags:          tags(span.Metadata()),
+	}
+
+	if span.Panicked {
+		message := fmt.Sprint(span.Error)
+		encoded.Tags["error"] = message
+		encoded.Annotations = []annotation{{Timestamp: span.End.UnixMicro(), Value: "panic: " + message}}
+	}
+
+	return encoded
+}
+
+// converts the metadata to tags, nil values are skipped
+func tags(metadata map[string]interface{}) map[string]string {
+	encoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		switch value := value.(type) {
+		case nil:
+			continue
+		case error:
+			encoded[key] = value.Error()
+		default:
+			encoded[key] = fmt.Sprint(value)
+		}
+	}
+
+	return encoded
+}
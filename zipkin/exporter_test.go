@@ -0,0 +1,194 @@
+package zipkin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	telemetry "github.com/trexreigns/gopulse"
+	"github.com/trexreigns/gopulse/providers"
+	"github.com/trexreigns/gopulse/zipkin"
+)
+
+// a zipkin stand in recording the spans it receives
+type server struct {
+	mu       sync.Mutex
+	requests int
+	spans    []map[string]interface{}
+	status   int
+}
+
+func newServer(t *testing.T, status int) (*server, *httptest.Server) {
+	s := &server{status: status}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/spans" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var spans []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&spans); err != nil {
+			t.Errorf("received invalid json: %v", err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests++
+		s.spans = append(s.spans, spans...)
+		w.WriteHeader(s.status)
+	}))
+	t.Cleanup(httpServer.Close)
+
+	return s, httpServer
+}
+
+func (s *server) received() (int, []map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests, append([]map[string]interface{}{}, s.spans...)
+}
+
+func newExporter(t *testing.T, config *zipkin.Config) *zipkin.Exporter {
+	exporter, err := zipkin.NewExporter("zipkin", config)
+	if err != nil {
+		t.Fatalf("failed to create the exporter: %v", err)
+	}
+
+	return exporter
+}
+
+func okSpan() (any, error, map[string]interface{}, map[string]interface{}) {
+	time.Sleep(2 * time.Millisecond)
+	return nil, nil, map[string]interface{}{}, map[string]interface{}{"result": "ok"}
+}
+
+func TestExporter(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	zipkinServer, httpServer := newServer(t, http.StatusAccepted)
+
+	exporter := newExporter(t, zipkin.NewConfig(
+		zipkin.WithEndpoint(httpServer.URL+"/api/v2/spans"),
+		zipkin.WithServiceName("checkout"),
+		zipkin.WithSpans("gopulse.checkout"),
+		zipkin.WithFlushInterval(time.Hour),
+	))
+	telemetry.AddHandlers(exporter)
+
+	telemetry.TriggerSpan("gopulse.checkout", map[string]interface{}{"user": 42}, okSpan)
+	func() {
+		defer func() {
+			recover()
+		}()
+
+		telemetry.TriggerSpan("gopulse.checkout", map[string]interface{}{}, func() (any, error, map[string]interface{}, map[string]interface{}) {
+			panic(errors.New("card declined"))
+		})
+	}()
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+
+	requests, spans := zipkinServer.received()
+	if requests != 1 || len(spans) != 2 {
+		t.Fatalf("expected a single request with 2 spans, got %d requests with %d spans", requests, len(spans))
+	}
+
+	ok := spans[0]
+	if ok["name"] != "gopulse.checkout" || len(ok["traceId"].(string)) != 32 || len(ok["id"].(string)) != 16 {
+		t.Errorf("unexpected span %v", ok)
+	}
+
+	if ok["duration"].(float64) < 1000 || ok["timestamp"].(float64) <= 0 {
+		t.Errorf("expected the span timing in microseconds, got %v", ok)
+	}
+
+	if ok["localEndpoint"].(map[string]interface{})["serviceName"] != "checkout" {
+		t.Errorf("unexpected local endpoint %v", ok["localEndpoint"])
+	}
+
+	tags := ok["tags"].(map[string]interface{})
+	if tags["user"] != "42" || tags["result"] != "ok" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	panicked := spans[1]
+	if panicked["tags"].(map[string]interface{})["error"] != "card declined" {
+		t.Errorf("expected the panic to be tagged as an error, got %v", panicked["tags"])
+	}
+
+	if annotations := panicked["annotations"].([]interface{}); len(annotations) != 1 {
+		t.Errorf("expected a panic annotation, got %v", annotations)
+	}
+}
+
+func TestExporterBatches(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	zipkinServer, httpServer := newServer(t, http.StatusAccepted)
+
+	exporter := newExporter(t, zipkin.NewConfig(
+		zipkin.WithEndpoint(httpServer.URL+"/api/v2/spans"),
+		zipkin.WithSpans("gopulse.job"),
+		zipkin.WithBatchSize(3),
+		zipkin.WithFlushInterval(time.Hour),
+	))
+	telemetry.AddHandlers(exporter)
+
+	for i := 0; i < 7; i++ {
+		telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, okSpan)
+	}
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("failed to shutdown: %v", err)
+	}
+
+	requests, spans := zipkinServer.received()
+	if len(spans) != 7 || requests < 3 {
+		t.Errorf("expected 7 spans in at least 3 batches, got %d spans in %d requests", len(spans), requests)
+	}
+}
+
+func TestExporterRejectsInvalidConfig(t *testing.T) {
+	invalid := map[error]zipkin.ConfigUpdateFunc{
+		zipkin.ErrInvalidBatchSize:     zipkin.WithBatchSize(0),
+		zipkin.ErrInvalidMaxQueueSize:  zipkin.WithMaxQueueSize(-1),
+		zipkin.ErrInvalidFlushInterval: zipkin.WithFlushInterval(0),
+	}
+
+	for expected, config := range invalid {
+		if _, err := zipkin.NewExporter("zipkin", zipkin.NewConfig(config)); !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+}
+
+func TestExporterCountsDroppedSpans(t *testing.T) {
+	// register telemetry
+	telemetry := providers.NewTelemetry(telemetry.NewTelemetryConfig())
+	_, httpServer := newServer(t, http.StatusInternalServerError)
+
+	exporter := newExporter(t, zipkin.NewConfig(
+		zipkin.WithEndpoint(httpServer.URL+"/api/v2/spans"),
+		zipkin.WithSpans("gopulse.job"),
+		zipkin.WithFlushInterval(time.Hour),
+	))
+	telemetry.AddHandlers(exporter)
+
+	telemetry.TriggerSpan("gopulse.job", map[string]interface{}{}, okSpan)
+
+	if err := exporter.Shutdown(context.Background()); err == nil {
+		t.Fatal("expected the post to fail")
+	}
+
+	if exporter.Dropped() != 1 {
+		t.Errorf("expected 1 dropped span, got %d", exporter.Dropped())
+	}
+}